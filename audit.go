@@ -0,0 +1,322 @@
+package tavily
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditRecord represents a single call recorded by the audit log.
+type AuditRecord struct {
+	Time          time.Time         `json:"time"`
+	Session       []string          `json:"session"` // Names of the sessions from the root session to the one the call has been made on.
+	Labels        map[string]string `json:"labels,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"`
+	Operation     string            `json:"operation"` // EndpointSearch or EndpointExtract.
+	Search        *SearchQuery      `json:"search,omitempty"`
+	Extract       *ExtractRequest   `json:"extract,omitempty"`
+	Status        int               `json:"status,omitempty"` // HTTP status code of the API response, 0 if the API has not been reached.
+	Error         string            `json:"error,omitempty"`
+	Credits       float64           `json:"credits"`
+	Latency       time.Duration     `json:"-"`
+	SearchAnswer  *SearchAnswer     `json:"search_answer,omitempty"`  // Only if the audit log includes answers.
+	ExtractAnswer *ExtractAnswer    `json:"extract_answer,omitempty"` // Only if the audit log includes answers.
+}
+
+func (ar *AuditRecord) UnmarshalJSON(data []byte) (err error) {
+	type mask AuditRecord
+	tmp := struct {
+		*mask
+		Latency float64 `json:"latency"`
+	}{
+		mask: (*mask)(ar),
+	}
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON into tmp struct: %w", err)
+	}
+	ar.Latency = time.Duration(tmp.Latency * float64(time.Second))
+	return
+}
+
+func (ar AuditRecord) MarshalJSON() ([]byte, error) {
+	type mask AuditRecord
+	tmp := struct {
+		mask
+		Latency float64 `json:"latency"`
+	}{
+		mask:    mask(ar),
+		Latency: ar.Latency.Seconds(),
+	}
+	return json.Marshal(tmp)
+}
+
+// AuditLogConfig configures an audit log.
+type AuditLogConfig struct {
+	Path           string        // Path of the current JSONL file. Rotated files are renamed with a timestamp suffix next to it.
+	MaxSize        int64         // Optional size (in bytes) after which the file is rotated.
+	MaxAge         time.Duration // Optional age after which the file is rotated.
+	IncludeAnswers bool          // Include the API answers within the records.
+	OnError        func(error)   // Optional callback receiving the write and rotation errors, as calls can not fail because of the audit log.
+}
+
+// AuditLog is an append only JSONL log of every call made thru a client (see ClientConfig).
+type AuditLog struct {
+	conf AuditLogConfig
+	now  func() time.Time
+	// State
+	access  sync.Mutex
+	file    *os.File
+	size    int64
+	opened  time.Time
+	encoder *json.Encoder
+	renamed bool // the current file has been rotated but the new one could not be opened yet
+}
+
+// NewAuditLog opens (or creates) the audit log file. Records are appended to an existing file.
+func NewAuditLog(conf AuditLogConfig) (al *AuditLog, err error) {
+	if conf.Path == "" {
+		return nil, errors.New("path is required")
+	}
+	if conf.MaxSize < 0 || conf.MaxAge < 0 {
+		return nil, errors.New("rotation thresholds must be non-negative")
+	}
+	al = &AuditLog{
+		conf: conf,
+		now:  time.Now,
+	}
+	if err = al.open(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// open opens the file at the log path as the current file. The state is left untouched on failure.
+func (al *AuditLog) open() (err error) {
+	file, err := os.OpenFile(al.conf.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat audit log file: %w", err)
+	}
+	al.file = file
+	al.size = info.Size()
+	al.opened = al.now()
+	al.encoder = json.NewEncoder(&countingWriter{w: al.file, n: &al.size})
+	return
+}
+
+// Write appends a record to the log, rotating the file first if needed. If the rotation fails, its error is returned
+// but the record is still appended to the current file and the rotation is retried on the next write.
+func (al *AuditLog) Write(record AuditRecord) (err error) {
+	al.access.Lock()
+	defer al.access.Unlock()
+	if al.file == nil {
+		return errors.New("audit log is closed")
+	}
+	var rotateErr error
+	if al.size > 0 && ((al.conf.MaxSize > 0 && al.size >= al.conf.MaxSize) ||
+		(al.conf.MaxAge > 0 && al.now().Sub(al.opened) >= al.conf.MaxAge)) {
+		rotateErr = al.rotate()
+	}
+	if err = al.encoder.Encode(record); err != nil {
+		return errors.Join(rotateErr, fmt.Errorf("failed to write audit record: %w", err))
+	}
+	return rotateErr
+}
+
+// rotate renames the current file and opens a new one. On failure, the current file stays open.
+func (al *AuditLog) rotate() (err error) {
+	if !al.renamed {
+		if err = os.Rename(al.conf.Path, rotatedAuditLogPath(al.conf.Path, al.now())); err != nil {
+			return fmt.Errorf("failed to rotate audit log file: %w", err)
+		}
+		al.renamed = true
+	}
+	previous := al.file
+	if err = al.open(); err != nil {
+		// keep appending to the renamed file until a new one can be opened
+		return fmt.Errorf("failed to rotate audit log file: %w", err)
+	}
+	al.renamed = false
+	if err = previous.Close(); err != nil {
+		return fmt.Errorf("failed to close rotated audit log file: %w", err)
+	}
+	return
+}
+
+// Close closes the log file. Records written afterwards are rejected.
+func (al *AuditLog) Close() (err error) {
+	al.access.Lock()
+	defer al.access.Unlock()
+	if al.file == nil {
+		return
+	}
+	err = al.file.Close()
+	al.file = nil
+	return
+}
+
+// record builds and writes the record of a call. A nil audit log does nothing.
+func (al *AuditLog) record(event UsageEvent, status int, result callResult) {
+	if al == nil {
+		return
+	}
+	record := AuditRecord{
+		Time:      event.Time,
+		Session:   event.SessionPath,
+		Labels:    event.Labels,
+		Tags:      event.Tags,
+		Operation: event.Operation,
+		Search:    result.searchQuery,
+		Extract:   result.extractRequest,
+		Status:    status,
+		Credits:   event.Credits,
+		Latency:   event.Latency,
+	}
+	if event.Err != nil {
+		record.Error = event.Err.Error()
+	} else if al.conf.IncludeAnswers {
+		record.SearchAnswer = result.searchAnswer
+		record.ExtractAnswer = result.extractAnswer
+	}
+	if err := al.Write(record); err != nil && al.conf.OnError != nil {
+		al.conf.OnError(err)
+	}
+}
+
+const auditLogRotationLayout = "20060102T150405.000000000"
+
+// rotatedAuditLogPath returns the path of a rotated file: "audit.jsonl" becomes "audit-<timestamp>.jsonl".
+func rotatedAuditLogPath(path string, t time.Time) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + t.UTC().Format(auditLogRotationLayout) + ext
+}
+
+// AuditLogFiles returns the files of the audit log at path (see AuditLogConfig): the rotated ones in chronological order,
+// then the current one if it exists.
+func AuditLogFiles(path string) (files []string, err error) {
+	ext := filepath.Ext(path)
+	prefix := strings.TrimSuffix(path, ext) + "-"
+	candidates, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rotated files: %w", err)
+	}
+	for _, candidate := range candidates {
+		// ignore the files sharing the prefix without being rotated ones (eg "audit-old.jsonl")
+		timestamp := strings.TrimSuffix(strings.TrimPrefix(candidate, prefix), ext)
+		if _, err = time.Parse(auditLogRotationLayout, timestamp); err == nil && len(timestamp) == len(auditLogRotationLayout) {
+			files = append(files, candidate)
+		}
+	}
+	slices.Sort(files) // timestamps sort lexicographically
+	if _, err = os.Stat(path); err == nil {
+		files = append(files, path)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to stat current file: %w", err)
+	}
+	return files, nil
+}
+
+// ReadAuditLog iterates over the records of a JSONL audit log stream. Iteration stops after the first error.
+func ReadAuditLog(r io.Reader) iter.Seq2[AuditRecord, error] {
+	return func(yield func(AuditRecord, error) bool) {
+		decoder := json.NewDecoder(r)
+		for {
+			var record AuditRecord
+			err := decoder.Decode(&record)
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				yield(record, fmt.Errorf("failed to decode audit record: %w", err))
+				return
+			}
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}
+}
+
+// ReadAuditLogFiles iterates over the records of every file of the audit log at path, oldest first (see AuditLogFiles).
+// The files are opened and their sizes snapshotted when the iteration starts: records appended afterwards (eg by a client
+// replaying them while auditing into the same log) are not included, and files rotated meanwhile are still read.
+// Iteration stops after the first error.
+func ReadAuditLogFiles(path string) iter.Seq2[AuditRecord, error] {
+	return func(yield func(AuditRecord, error) bool) {
+		paths, err := AuditLogFiles(path)
+		if err != nil {
+			yield(AuditRecord{}, err)
+			return
+		}
+		files := make([]snapshottedFile, 0, len(paths))
+		defer func() {
+			for _, file := range files {
+				file.fd.Close()
+			}
+		}()
+		for _, path := range paths {
+			file, err := snapshotFile(path)
+			if err != nil {
+				yield(AuditRecord{}, err)
+				return
+			}
+			files = append(files, file)
+		}
+		for _, file := range files {
+			for record, err := range ReadAuditLog(io.LimitReader(file.fd, file.size)) {
+				if err != nil {
+					yield(record, fmt.Errorf("%s: %w", file.path, err))
+					return
+				}
+				if !yield(record, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// snapshottedFile is an opened file read up to its size at opening time.
+type snapshottedFile struct {
+	path string
+	fd   *os.File
+	size int64
+}
+
+func snapshotFile(path string) (file snapshottedFile, err error) {
+	file.path = path
+	if file.fd, err = os.Open(path); err != nil {
+		return file, fmt.Errorf("failed to open audit log file: %w", err)
+	}
+	info, err := file.fd.Stat()
+	if err != nil {
+		file.fd.Close()
+		return file, fmt.Errorf("failed to stat audit log file: %w", err)
+	}
+	file.size = info.Size()
+	return
+}
+
+// countingWriter counts the bytes written thru it.
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (cw *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = cw.w.Write(p)
+	*cw.n += int64(n)
+	return
+}
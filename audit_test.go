@@ -0,0 +1,148 @@
+package tavily
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func readTestAuditLog(t *testing.T, path string) (records []AuditRecord) {
+	t.Helper()
+	for record, err := range ReadAuditLogFiles(path) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	return
+}
+
+func TestAuditLogRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit, err := NewAuditLog(AuditLogConfig{Path: path, IncludeAnswers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := newTestClient(t, &fakeAPI{}, ClientConfig{AuditLog: audit})
+	session, err := root.NewSessionWithConfig(SessionConfig{Name: "tenant", Labels: map[string]string{"tenant": "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = session.Search(WithTag(context.Background(), "user_id", "42"), SearchQuery{Query: "q"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = audit.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = audit.Write(AuditRecord{}); err == nil {
+		t.Error("expected a closed audit log to reject records")
+	}
+	records := readTestAuditLog(t, path)
+	if len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(records))
+	}
+	record := records[0]
+	if record.Operation != EndpointSearch || record.Search == nil || record.Search.Query != "q" || record.Status != 200 ||
+		record.Credits != 1 || record.SearchAnswer == nil || len(record.SearchAnswer.Results) != 1 {
+		t.Errorf("unexpected record: %+v", record)
+	}
+	if !slices.Equal(record.Session, []string{"", "tenant"}) || record.Labels["tenant"] != "a" || record.Tags["user_id"] != "42" {
+		t.Errorf("unexpected record attribution: %+v", record)
+	}
+}
+
+func TestAuditLogRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit, err := NewAuditLog(AuditLogConfig{Path: path, MaxSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer audit.Close()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	audit.now = func() time.Time { return now }
+	for _, operation := range []string{"a", "b", "c"} {
+		now = now.Add(time.Second)
+		if err = audit.Write(AuditRecord{Operation: operation}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := AuditLogFiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 3 || files[2] != path {
+		t.Fatalf("expected 2 rotated files and the current one, got %v", files)
+	}
+	var operations []string
+	for _, record := range readTestAuditLog(t, path) {
+		operations = append(operations, record.Operation)
+	}
+	if !slices.Equal(operations, []string{"a", "b", "c"}) {
+		t.Errorf("expected the records in chronological order, got %v", operations)
+	}
+}
+
+func TestAuditLogRotationFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit, err := NewAuditLog(AuditLogConfig{Path: path, MaxSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer audit.Close()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	audit.now = func() time.Time { return now }
+	if err = audit.Write(AuditRecord{Operation: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	// a non empty directory at the rotated path makes the rename fail
+	blocker := rotatedAuditLogPath(path, now)
+	if err = os.MkdirAll(filepath.Join(blocker, "child"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err = audit.Write(AuditRecord{Operation: "b"}); err == nil {
+		t.Fatal("expected the rotation error")
+	}
+	// the record has been appended to the current file and the rotation is retried on the next write
+	if err = os.RemoveAll(blocker); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Second)
+	if err = audit.Write(AuditRecord{Operation: "c"}); err != nil {
+		t.Fatal(err)
+	}
+	files, err := AuditLogFiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0] != rotatedAuditLogPath(path, now) {
+		t.Fatalf("unexpected files: %v", files)
+	}
+	var operations []string
+	for _, record := range readTestAuditLog(t, path) {
+		operations = append(operations, record.Operation)
+	}
+	if !slices.Equal(operations, []string{"a", "b", "c"}) {
+		t.Errorf("expected no record to be lost, got %v", operations)
+	}
+}
+
+func TestAuditLogFilesIgnoresForeignFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.jsonl")
+	rotated := rotatedAuditLogPath(path, time.Now())
+	for _, file := range []string{rotated, filepath.Join(dir, "audit-old.jsonl"), filepath.Join(dir, "audit-20250101T000000.jsonl")} {
+		if err := os.WriteFile(file, nil, 0o640); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := AuditLogFiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(files, []string{rotated}) {
+		t.Errorf("expected only the rotated file (the current one does not exist), got %v", files)
+	}
+}
@@ -0,0 +1,96 @@
+package tavily
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// call holds the information gathered while a single API call goes thru the sessions tree down to the main client.
+// It is created by the first session handling the call and carried by the context.
+type call struct {
+	origin        *Session // the session the call has been made on
+	start         time.Time
+	tags          map[string]string
+	queueWait     atomic.Int64 // nanoseconds
+	rateLimitWait atomic.Int64 // nanoseconds
+	status        int          // HTTP status code of the last API response
+}
+
+// callResult is what the session the call has been made on knows once the call is done.
+type callResult struct {
+	operation      string
+	depth          string
+	usage          Stats
+	err            error
+	searchQuery    *SearchQuery
+	searchAnswer   *SearchAnswer
+	extractRequest *ExtractRequest
+	extractAnswer  *ExtractAnswer
+}
+
+type callCtxKey struct{}
+
+// startCall returns the call carried by ctx, or a new one originating from s (and the context carrying it)
+// if ctx does not have one yet.
+func startCall(ctx context.Context, s *Session) (context.Context, *call) {
+	if c, ok := ctx.Value(callCtxKey{}).(*call); ok {
+		return ctx, c
+	}
+	c := &call{
+		origin: s,
+		start:  time.Now(),
+		tags:   TagsFromContext(ctx),
+	}
+	return context.WithValue(ctx, callCtxKey{}, c), c
+}
+
+// callFromContext returns the call carried by ctx, nil if none.
+func callFromContext(ctx context.Context) *call {
+	c, _ := ctx.Value(callCtxKey{}).(*call)
+	return c
+}
+
+func (c *call) setStatus(statusCode int) {
+	if c != nil {
+		c.status = statusCode
+	}
+}
+
+// end is called by each session handling a call once done. The session the call has been made on (the first one)
+// publishes the usage event and writes the audit record, if enabled.
+func (s *Session) end(c *call, result callResult) {
+	if c.origin != s {
+		return
+	}
+	if result.depth == "" {
+		result.depth = "basic"
+	}
+	now := time.Now()
+	event := UsageEvent{
+		Time:        now,
+		Operation:   result.operation,
+		Depth:       result.depth,
+		Usage:       result.usage,
+		Credits:     result.usage.TotalCost(),
+		SessionPath: s.path(),
+		Labels:      s.inheritedLabels(),
+		Tags:        c.tags,
+		Latency:     now.Sub(c.start),
+		Err:         result.err,
+	}
+	s.publishUsage(event)
+	s.root.audit.record(event, c.status, result)
+}
+
+func (c *call) addQueueWait(d time.Duration) {
+	if c != nil {
+		c.queueWait.Add(int64(d))
+	}
+}
+
+func (c *call) addRateLimitWait(d time.Duration) {
+	if c != nil {
+		c.rateLimitWait.Add(int64(d))
+	}
+}
@@ -0,0 +1,191 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without contacting the API while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open: Tavily API seems unavailable")
+
+// CircuitState represents the state of the circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed is the nominal state: requests are sent to the API.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen means the API is considered unavailable: requests fail fast with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen means a single probe request is allowed to check if the API is available again.
+	CircuitHalfOpen
+)
+
+func (cs CircuitState) String() string {
+	switch cs {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker configures the circuit breaker of the client. It opens after consecutive server errors (5xx) or
+// transport failures (including timeouts), then half-opens after OpenDuration to let a probe request thru.
+type CircuitBreaker struct {
+	FailureThreshold int                         // Consecutive failures needed to open the circuit. Default is 5.
+	OpenDuration     time.Duration               // Time the circuit stays open before letting a probe thru. Default is 30s.
+	OnStateChange    func(from, to CircuitState) // Optional callback called (synchronously) on each state change.
+}
+
+const (
+	defaultCircuitFailureThreshold = 5
+	defaultCircuitOpenDuration     = 30 * time.Second
+)
+
+func (cb CircuitBreaker) validate() error {
+	switch {
+	case cb.FailureThreshold < 0:
+		return errors.New("failure threshold must be a non-negative integer")
+	case cb.OpenDuration < 0:
+		return errors.New("open duration must be non-negative")
+	}
+	return nil
+}
+
+// breakerOutcome qualifies the result of a request from the circuit breaker point of view.
+type breakerOutcome int
+
+const (
+	breakerIgnored breakerOutcome = iota // the API has not been reached (eg request canceled)
+	breakerSuccess                       // the API answered (even with a client error)
+	breakerFailure                       // server error or transport failure
+)
+
+// circuitBreaker is the runtime state of the circuit breaker. A nil circuitBreaker lets everything thru.
+type circuitBreaker struct {
+	conf CircuitBreaker
+	// State
+	access        sync.Mutex
+	state         CircuitState
+	failures      int
+	openedAt      time.Time
+	probeInFlight bool
+}
+
+func newCircuitBreaker(conf CircuitBreaker) *circuitBreaker {
+	if conf.FailureThreshold == 0 {
+		conf.FailureThreshold = defaultCircuitFailureThreshold
+	}
+	if conf.OpenDuration == 0 {
+		conf.OpenDuration = defaultCircuitOpenDuration
+	}
+	return &circuitBreaker{
+		conf: conf,
+	}
+}
+
+// isOpen returns true if requests would be rejected right now. It does not change the breaker state and
+// allows to fail fast before waiting for any limiter.
+func (cb *circuitBreaker) isOpen() bool {
+	if cb == nil {
+		return false
+	}
+	cb.access.Lock()
+	defer cb.access.Unlock()
+	switch cb.state {
+	case CircuitOpen:
+		return time.Since(cb.openedAt) < cb.conf.OpenDuration
+	case CircuitHalfOpen:
+		return cb.probeInFlight
+	default:
+		return false
+	}
+}
+
+// allow returns ErrCircuitOpen if the request must not be sent. Otherwise the caller must report the request
+// outcome with record, probe being true if the request is the half open probe.
+func (cb *circuitBreaker) allow() (probe bool, err error) {
+	if cb == nil {
+		return
+	}
+	cb.access.Lock()
+	from := cb.state
+	switch cb.state {
+	case CircuitOpen:
+		if time.Since(cb.openedAt) < cb.conf.OpenDuration {
+			err = ErrCircuitOpen
+			break
+		}
+		cb.state = CircuitHalfOpen
+		fallthrough
+	case CircuitHalfOpen:
+		if cb.probeInFlight {
+			err = ErrCircuitOpen
+			break
+		}
+		cb.probeInFlight = true
+		probe = true
+	}
+	to := cb.state
+	cb.access.Unlock()
+	cb.notify(from, to)
+	return
+}
+
+// record updates the breaker state with the outcome of an allowed request.
+func (cb *circuitBreaker) record(probe bool, outcome breakerOutcome) {
+	if cb == nil {
+		return
+	}
+	cb.access.Lock()
+	from := cb.state
+	if probe {
+		cb.probeInFlight = false
+	}
+	switch outcome {
+	case breakerSuccess:
+		cb.failures = 0
+		if cb.state == CircuitHalfOpen && probe {
+			cb.state = CircuitClosed
+		}
+	case breakerFailure:
+		cb.failures++
+		if (cb.state == CircuitHalfOpen && probe) ||
+			(cb.state == CircuitClosed && cb.failures >= cb.conf.FailureThreshold) {
+			cb.state = CircuitOpen
+			cb.openedAt = time.Now()
+		}
+	}
+	to := cb.state
+	cb.access.Unlock()
+	cb.notify(from, to)
+}
+
+func (cb *circuitBreaker) notify(from, to CircuitState) {
+	if from != to && cb.conf.OnStateChange != nil {
+		cb.conf.OnStateChange(from, to)
+	}
+}
+
+// transportOutcome qualifies an HTTP client error: a canceled context is the caller decision, anything else
+// (including timeouts) means the API could not be reached in time.
+func transportOutcome(err error) breakerOutcome {
+	if errors.Is(err, context.Canceled) {
+		return breakerIgnored
+	}
+	return breakerFailure
+}
+
+// statusOutcome qualifies an API response status code.
+func statusOutcome(statusCode int) breakerOutcome {
+	if statusCode >= 500 {
+		return breakerFailure
+	}
+	return breakerSuccess
+}
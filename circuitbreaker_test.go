@@ -0,0 +1,155 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	api := &fakeAPI{handler: func(req *http.Request, payload map[string]any) *http.Response {
+		if failing.Load() {
+			return jsonResponse(http.StatusServiceUnavailable, `{"detail":{"error":"unavailable"}}`)
+		}
+		return defaultFakeHandler(req, payload)
+	}}
+	var (
+		access      sync.Mutex
+		transitions []CircuitState
+	)
+	client := newTestClient(t, api, ClientConfig{CircuitBreaker: &CircuitBreaker{
+		FailureThreshold: 2,
+		OpenDuration:     30 * time.Millisecond,
+		OnStateChange: func(from, to CircuitState) {
+			access.Lock()
+			defer access.Unlock()
+			transitions = append(transitions, to)
+		},
+	}})
+	search := func() error {
+		_, err := client.Search(context.Background(), SearchQuery{Query: "q"})
+		return err
+	}
+	// consecutive failures open the circuit
+	for range 2 {
+		var apiErr APIError
+		if err := search(); !errors.As(err, &apiErr) {
+			t.Fatalf("expected an API error, got %v", err)
+		}
+	}
+	if err := search(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the circuit to be open, got %v", err)
+	}
+	if len(api.calls()) != 2 {
+		t.Fatalf("expected the API not to be contacted while open, got %d calls", len(api.calls()))
+	}
+	// a failed probe opens it again
+	time.Sleep(40 * time.Millisecond)
+	if err := search(); errors.Is(err, ErrCircuitOpen) || err == nil {
+		t.Fatalf("expected the probe to reach the API, got %v", err)
+	}
+	if err := search(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the circuit to be open again, got %v", err)
+	}
+	// a successful probe closes it
+	failing.Store(false)
+	time.Sleep(40 * time.Millisecond)
+	testSearch(t, client)
+	testSearch(t, client)
+	access.Lock()
+	defer access.Unlock()
+	expected := []CircuitState{CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed}
+	if len(transitions) != len(expected) {
+		t.Fatalf("expected transitions %v, got %v", expected, transitions)
+	}
+	for index := range expected {
+		if transitions[index] != expected[index] {
+			t.Fatalf("expected transitions %v, got %v", expected, transitions)
+		}
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	cb := newCircuitBreaker(CircuitBreaker{FailureThreshold: 1})
+	for _, outcome := range []breakerOutcome{statusOutcome(http.StatusBadRequest), statusOutcome(http.StatusTooManyRequests), transportOutcome(context.Canceled)} {
+		probe, err := cb.allow()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cb.record(probe, outcome)
+	}
+	if cb.isOpen() {
+		t.Fatal("expected client errors and cancellations not to open the circuit")
+	}
+	probe, _ := cb.allow()
+	cb.record(probe, transportOutcome(context.DeadlineExceeded))
+	if !cb.isOpen() {
+		t.Fatal("expected a timeout to open the circuit")
+	}
+}
+
+func TestCircuitStateString(t *testing.T) {
+	for state, expected := range map[CircuitState]string{CircuitClosed: "closed", CircuitOpen: "open", CircuitHalfOpen: "half-open", 42: "unknown"} {
+		if state.String() != expected {
+			t.Errorf("expected %q, got %q", expected, state.String())
+		}
+	}
+}
+
+func TestCircuitBreakerProbeAfterQueueing(t *testing.T) {
+	// the first call waiting for a rate limiter after the circuit opened stays stuck until released
+	var (
+		blocking atomic.Bool
+		blocked  = make(chan struct{})
+		release  = make(chan struct{})
+	)
+	limiter := funcLimiter(func(ctx context.Context) error {
+		if blocking.CompareAndSwap(true, false) {
+			close(blocked)
+			<-release
+		}
+		return nil
+	})
+	var failing atomic.Bool
+	failing.Store(true)
+	api := &fakeAPI{handler: func(req *http.Request, payload map[string]any) *http.Response {
+		if failing.Load() {
+			return jsonResponse(http.StatusServiceUnavailable, `{"detail":{"error":"unavailable"}}`)
+		}
+		return defaultFakeHandler(req, payload)
+	}}
+	client := newTestClient(t, api, ClientConfig{
+		APIKeys: []APIKey{
+			{Key: "tvly-dev-testkey0001", RateLimiter: limiter},
+			{Key: "tvly-dev-testkey0002", RateLimiter: limiter},
+		},
+		CircuitBreaker: &CircuitBreaker{
+			FailureThreshold: 1,
+			OpenDuration:     10 * time.Millisecond,
+		},
+	})
+	if _, err := client.Search(context.Background(), SearchQuery{Query: "q"}); err == nil {
+		t.Fatal("expected the first call to fail")
+	}
+	failing.Store(false)
+	blocking.Store(true)
+	time.Sleep(20 * time.Millisecond)
+	// a call stuck in the rate limiter of a key must not hold the half open probe
+	done := make(chan error)
+	go func() {
+		_, err := client.Search(context.Background(), SearchQuery{Query: "q"})
+		done <- err
+	}()
+	<-blocked
+	testSearch(t, client)
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("expected the queued call to succeed once the circuit is closed, got %v", err)
+	}
+}
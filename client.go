@@ -3,14 +3,16 @@ package tavily
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/hashicorp/go-cleanhttp"
-	"golang.org/x/time/rate"
 )
 
+// Client is implemented by *Session, which provides the additional features of the client (sessions tree, usage events,
+// keys stats, etc...). Clients returned by NewClient and NewSession can be type asserted to *Session to access them.
 type Client interface {
 	Search(context.Context, SearchQuery) (SearchAnswer, error)
 	Extract(context.Context, ExtractRequest) (ExtractAnswer, error)
@@ -27,39 +29,111 @@ const (
 	ReqPerMinuteProd = 1000
 )
 
+// Key prefixes used to detect the default rate limit of an API key when it is not explicitly set.
 const (
 	devKeyPrefix  = "tvly-dev-"
 	prodKeyPrefix = "tvly-prod-"
 )
 
-func NewClient(APIKey string, customHTTPClient *http.Client) (c Client, err error) {
-	var reqPerMinute int
-	switch {
-	case strings.HasPrefix(APIKey, devKeyPrefix):
-		reqPerMinute = ReqPerMinuteDev
-	case strings.HasPrefix(APIKey, prodKeyPrefix):
-		reqPerMinute = ReqPerMinuteProd
-	default:
-		err = errors.New("APIKey does not seem to be a valid Tavily API key")
+// NewClient returns a client using a single API key. Use NewClientWithConfig for more advanced use cases.
+func NewClient(apiKey string, customHTTPClient *http.Client) (c Client, err error) {
+	root, err := NewClientWithConfig(ClientConfig{
+		APIKeys:    []APIKey{{Key: apiKey}},
+		HTTPClient: customHTTPClient,
+	})
+	if err != nil {
 		return
 	}
-	if customHTTPClient == nil {
-		customHTTPClient = cleanhttp.DefaultPooledClient()
+	return root, nil
+}
+
+// ClientConfig allows to customize the client.
+type ClientConfig struct {
+	APIKeys     []APIKey    // The pool of API keys the client can use. At least one is required.
+	KeyStrategy KeyStrategy // How an API key is selected from the pool for each request. Default is KeyStrategyRoundRobin.
+	// Optional time after which a key disabled by an authentication or quota error (eg a monthly quota reached or a rotated key)
+	// is tried again, and put back in the rotation if accepted. Default is DefaultDisabledKeyCooldown, negative never tries them again.
+	// The last available key of the pool is never disabled: its errors are returned instead.
+	DisabledKeyCooldown time.Duration
+	HTTPClient          *http.Client // Optional custom HTTP client. Default is a pooled cleanhttp client.
+	// Optional shared state store. When set, the rate limiters of the keys are shared with every other client
+	// (possibly in other processes) using the same store and the same keys. Keys with a custom RateLimiter are not affected.
+	SharedRateLimiterStore SharedRateLimiterStore
+	// Optional adaptive rate limiting, lowering the effective rate of a key when the API throttles it anyway.
+	AdaptiveRateLimit *AdaptiveRateLimit
+	// Optional waiting time after which a request queued behind higher priority ones (see WithPriority) gains one priority level,
+	// preventing starvation of low priority requests. Default is DefaultPriorityAging.
+	PriorityAging time.Duration
+	// Optional maximum number of in flight requests of the client (including all its sessions ones).
+	MaxConcurrency int
+	// Optional maximum number of values tracked per tag key by the root session (see SessionConfig.MaxTagValues).
+	MaxTagValues int
+	// Optional circuit breaker failing fast with ErrCircuitOpen while the API seems unavailable.
+	CircuitBreaker *CircuitBreaker
+	// Optional audit log recording every call made thru the client and its sessions.
+	AuditLog *AuditLog
+	// Optional writer receiving the raw HTTP requests and responses exchanged with the API (API keys redacted), for debugging.
+	DebugWriter io.Writer
+	// Keep the raw JSON responses of the API within the answers (see SearchAnswer.Raw and ExtractAnswer.Raw).
+	KeepRawResponses bool
+	// Optional callback enabling the strict decoding of the API responses: it receives the fields unknown by this package
+	// (always preserved in the Extra maps of the answers) and the values not matching their field type, which become
+	// non fatal (the field is left to its zero value) instead of failing the call.
+	OnSchemaDrift func(SchemaDrift)
+}
+
+// NewClientWithConfig returns the root session of a client configured with conf.
+func NewClientWithConfig(conf ClientConfig) (c *Session, err error) {
+	keys, err := newKeyPool(conf.APIKeys, keyPoolConfig{
+		strategy:      conf.KeyStrategy,
+		sharedStore:   conf.SharedRateLimiterStore,
+		adaptive:      conf.AdaptiveRateLimit,
+		priorityAging: conf.PriorityAging,
+		cooldown:      conf.DisabledKeyCooldown,
+	})
+	if err != nil {
+		err = fmt.Errorf("invalid API keys configuration: %w", err)
+		return
+	}
+	if conf.MaxConcurrency < 0 {
+		err = errors.New("max concurrency must be a non-negative integer")
+		return
+	}
+	var breaker *circuitBreaker
+	if conf.CircuitBreaker != nil {
+		if err = conf.CircuitBreaker.validate(); err != nil {
+			err = fmt.Errorf("invalid circuit breaker configuration: %w", err)
+			return
+		}
+		breaker = newCircuitBreaker(*conf.CircuitBreaker)
+	}
+	if conf.HTTPClient == nil {
+		conf.HTTPClient = cleanhttp.DefaultPooledClient()
 	}
 	mc := mainClient{
-		apiKey:     APIKey,
-		throughput: rate.NewLimiter(rate.Limit(reqPerMinute)/rate.Limit(time.Minute/time.Second), reqPerMinute),
-		httpClient: customHTTPClient,
+		keys:          keys,
+		concurrency:   newSemaphore(conf.MaxConcurrency),
+		breaker:       breaker,
+		audit:         conf.AuditLog,
+		debug:         newDebugDumper(conf.DebugWriter),
+		keepRaw:       conf.KeepRawResponses,
+		onSchemaDrift: conf.OnSchemaDrift,
+		httpClient:    conf.HTTPClient,
 	}
-	c = mc.NewSession()
+	c, _ = newSession(&mc, nil, SessionConfig{MaxTagValues: conf.MaxTagValues}) // can not fail without rate limit nor persistence
 	return
 }
 
 type mainClient struct {
-	apiKey string
 	// Controllers
-	throughput *rate.Limiter
-	httpClient *http.Client
+	keys          *keyPool
+	concurrency   semaphore
+	breaker       *circuitBreaker
+	audit         *AuditLog
+	debug         *debugDumper
+	keepRaw       bool
+	onSchemaDrift func(SchemaDrift)
+	httpClient    *http.Client
 }
 
 // main client does not hold stats as it is never returned directly to the client (a session is), just implementing interface here
@@ -69,7 +143,13 @@ func (c *mainClient) Stats() Stats {
 
 // creates a root session for this client
 func (c *mainClient) NewSession() Client {
-	return &session{
-		parent: c,
-	}
+	root, _ := newSession(c, nil, SessionConfig{}) // can not fail without config
+	return root
 }
+
+// DefaultDisabledKeyCooldown is the default time after which a disabled API key is tried again.
+const DefaultDisabledKeyCooldown = 10 * time.Minute
+
+// ErrNoAvailableAPIKey is returned when no API key of the pool is available. As the last available key is never disabled,
+// this only happens when concurrent requests get the remaining keys disabled at the same time.
+var ErrNoAvailableAPIKey = errors.New("no API key available in the pool")
@@ -0,0 +1,41 @@
+package tavily
+
+import (
+	"context"
+	"time"
+)
+
+// semaphore limits the number of in flight requests. A nil semaphore does not limit anything.
+type semaphore chan struct{}
+
+func newSemaphore(size int) semaphore {
+	if size <= 0 {
+		return nil
+	}
+	return make(semaphore, size)
+}
+
+// acquire blocks until a slot is available or ctx is done. It returns the time spent waiting for the slot.
+func (s semaphore) acquire(ctx context.Context) (waited time.Duration, err error) {
+	if s == nil {
+		return
+	}
+	select {
+	case s <- struct{}{}:
+		return
+	default:
+	}
+	start := time.Now()
+	select {
+	case s <- struct{}{}:
+		return time.Since(start), nil
+	case <-ctx.Done():
+		return time.Since(start), ctx.Err()
+	}
+}
+
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
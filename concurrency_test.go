@@ -0,0 +1,82 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSemaphore(t *testing.T) {
+	var unlimited semaphore
+	if _, err := unlimited.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	unlimited.release()
+	s := newSemaphore(1)
+	if waited, err := s.acquire(context.Background()); err != nil || waited != 0 {
+		t.Fatalf("expected a free slot, got %s %v", waited, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	waited, err := s.acquire(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || waited < 20*time.Millisecond {
+		t.Fatalf("expected to wait until the deadline, got %s %v", waited, err)
+	}
+	s.release()
+	if _, err = s.acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMaxConcurrency(t *testing.T) {
+	for name, conf := range map[string]struct {
+		client  int
+		session int
+	}{
+		"client":  {client: 2},
+		"session": {session: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var inFlight, maxInFlight atomic.Int64
+			api := &fakeAPI{handler: func(req *http.Request, payload map[string]any) *http.Response {
+				current := inFlight.Add(1)
+				defer inFlight.Add(-1)
+				for {
+					observed := maxInFlight.Load()
+					if current <= observed || maxInFlight.CompareAndSwap(observed, current) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				return defaultFakeHandler(req, payload)
+			}}
+			root := newTestClient(t, api, ClientConfig{MaxConcurrency: conf.client})
+			session, err := root.NewSessionWithConfig(SessionConfig{MaxConcurrency: conf.session})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var wg sync.WaitGroup
+			for range 6 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					testSearch(t, session)
+				}()
+			}
+			wg.Wait()
+			if got := maxInFlight.Load(); got != 2 {
+				t.Fatalf("expected at most 2 requests in flight, got %d", got)
+			}
+			if session.Stats().QueueWaitTime <= 0 {
+				t.Fatal("expected the queue wait time to be reported")
+			}
+		})
+	}
+	if _, err := NewClientWithConfig(ClientConfig{APIKeys: []APIKey{testKey("tvly-dev-testkey0001")}, MaxConcurrency: -1}); err == nil {
+		t.Fatal("expected an error for a negative concurrency")
+	}
+}
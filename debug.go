@@ -0,0 +1,84 @@
+package tavily
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DecodeError is returned when a successful API response can not be decoded. It carries the raw response body
+// (not included in the error message as it can be large).
+type DecodeError struct {
+	Err  error
+	Body []byte
+}
+
+func (e DecodeError) Error() string {
+	return fmt.Sprintf("failed to unmarshal response (%d bytes): %s", len(e.Body), e.Err)
+}
+
+func (e DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// rawKeeper is implemented by the answers able to keep their raw JSON response.
+type rawKeeper interface {
+	setRaw(json []byte)
+}
+
+// debugDumper writes the raw HTTP exchanges with the API (API keys redacted) to a writer.
+// Each exchange gets a sequence number as the dumps of concurrent requests are interleaved.
+type debugDumper struct {
+	w      io.Writer
+	access sync.Mutex
+	seq    atomic.Uint64
+}
+
+func newDebugDumper(w io.Writer) *debugDumper {
+	if w == nil {
+		return nil
+	}
+	return &debugDumper{w: w}
+}
+
+// request dumps req with its payload and returns the sequence number of the exchange.
+func (dd *debugDumper) request(req *http.Request, key *poolKey, payload []byte) (seq uint64) {
+	if dd == nil {
+		return
+	}
+	seq = dd.seq.Add(1)
+	header := req.Header.Clone()
+	header.Set("Authorization", "Bearer "+redactKey(key.value))
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--> #%d %s %s\n", seq, req.Method, req.URL)
+	_ = header.Write(&buf)
+	fmt.Fprintf(&buf, "\n%s\n\n", bytes.TrimRight(payload, "\n"))
+	dd.write(buf.Bytes())
+	return
+}
+
+// response dumps the response (or the transport error) of the exchange seq.
+func (dd *debugDumper) response(seq uint64, resp *http.Response, body []byte, latency time.Duration, err error) {
+	if dd == nil {
+		return
+	}
+	var buf bytes.Buffer
+	if err != nil {
+		fmt.Fprintf(&buf, "<-- #%d error (%s): %s\n\n", seq, latency, err)
+	} else {
+		fmt.Fprintf(&buf, "<-- #%d %d %s (%s)\n", seq, resp.StatusCode, statusText(resp.StatusCode), latency)
+		_ = resp.Header.Write(&buf)
+		fmt.Fprintf(&buf, "\n%s\n\n", bytes.TrimRight(body, "\n"))
+	}
+	dd.write(buf.Bytes())
+}
+
+func (dd *debugDumper) write(dump []byte) {
+	dd.access.Lock()
+	defer dd.access.Unlock()
+	_, _ = dd.w.Write(dump)
+}
@@ -0,0 +1,65 @@
+package tavily
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDebugDump(t *testing.T) {
+	var dump bytes.Buffer
+	root := newTestClient(t, &fakeAPI{}, ClientConfig{
+		APIKeys:     []APIKey{testKey("tvly-dev-secretkey0001")},
+		DebugWriter: &dump,
+	})
+	testSearch(t, root)
+	output := dump.String()
+	if strings.Contains(output, "secretkey0001") || !strings.Contains(output, "Bearer "+redactKey("tvly-dev-secretkey0001")) {
+		t.Errorf("expected the API key to be redacted:\n%s", output)
+	}
+	for _, expected := range []string{
+		"--> #1 POST https://api.tavily.com/search\n",
+		`"query":"q"`,
+		"<-- #1 200 OK (",
+		`"response_time":0.1}`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected the dump to contain %q:\n%s", expected, output)
+		}
+	}
+}
+
+func TestKeepRawResponses(t *testing.T) {
+	for _, keepRaw := range []bool{false, true} {
+		root := newTestClient(t, &fakeAPI{}, ClientConfig{KeepRawResponses: keepRaw})
+		answer := testSearch(t, root)
+		extract, err := root.Extract(context.Background(), ExtractRequest{URLs: []string{"https://example.com/"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keepRaw != (len(answer.Raw) > 0) || keepRaw != (len(extract.Raw) > 0) {
+			t.Errorf("keep raw responses %v: unexpected raw responses %q and %q", keepRaw, answer.Raw, extract.Raw)
+		}
+		if keepRaw && !bytes.Contains(extract.Raw, []byte(`"raw_content":"content of https://example.com/"`)) {
+			t.Errorf("unexpected raw response: %s", extract.Raw)
+		}
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	api := &fakeAPI{handler: func(*http.Request, map[string]any) *http.Response {
+		return jsonResponse(http.StatusOK, `{"query":`)
+	}}
+	root := newTestClient(t, api, ClientConfig{})
+	_, err := root.Search(context.Background(), SearchQuery{Query: "q"})
+	var decodeErr DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("expected a DecodeError, got %v", err)
+	}
+	if string(decodeErr.Body) != `{"query":` || decodeErr.Err == nil || strings.Contains(err.Error(), `{"query":`) {
+		t.Errorf("unexpected decode error: %v (body %q)", err, decodeErr.Body)
+	}
+}
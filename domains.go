@@ -0,0 +1,172 @@
+package tavily
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/hekmon/tavily/v2/urlcanon"
+)
+
+const (
+	// SearchMaxIncludeDomains is the maximum number of domains a search query can include, see https://docs.tavily.com/api-reference/endpoint/search#body-include-domains
+	SearchMaxIncludeDomains = 300
+	// SearchMaxExcludeDomains is the maximum number of domains a search query can exclude, see https://docs.tavily.com/api-reference/endpoint/search#body-exclude-domains
+	SearchMaxExcludeDomains = 150
+)
+
+// NormalizeDomain returns the canonical form of a domain filter: scheme, credentials, port, path and query are stripped,
+// the host is lowercased and internationalized domain names are converted to punycode.
+// Eg "https://WWW.Example.com:443/news?a=b" becomes "www.example.com" and "bücher.de" becomes "xn--bcher-kva.de".
+// Wildcards (eg "*.example.com") are not supported by the API filters and are rejected.
+func NormalizeDomain(domain string) (normalized string, err error) {
+	host := strings.TrimSpace(domain)
+	if strings.Contains(host, "://") {
+		parsed, err := url.Parse(host)
+		if err != nil {
+			return "", fmt.Errorf("invalid URL: %w", err)
+		}
+		host = parsed.Host
+	}
+	if index := strings.IndexAny(host, "/?#"); index >= 0 {
+		host = host[:index]
+	}
+	if index := strings.LastIndex(host, "@"); index >= 0 {
+		host = host[index+1:]
+	}
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	if strings.Contains(host, "*") {
+		return "", errors.New("wildcards are not supported")
+	}
+	if normalized, err = urlcanon.Host(host); err != nil {
+		return "", err
+	}
+	return
+}
+
+// NormalizeDomains returns the canonical form of each domain (see NormalizeDomain), without duplicates.
+func NormalizeDomains(domains []string) (normalized []string, err error) {
+	var verr ValidationError
+	normalized = normalizeDomainList("domains", domains, &verr)
+	if err = verr.err(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// normalizeDomainList returns the canonical form of each valid domain, without duplicates. Invalid domains are
+// reported into verr as field[index].
+func normalizeDomainList(field string, domains []string, verr *ValidationError) (normalized []string) {
+	if len(domains) == 0 {
+		return
+	}
+	normalized = make([]string, 0, len(domains))
+	seen := make(map[string]struct{}, len(domains))
+	for index, domain := range domains {
+		canonical, err := NormalizeDomain(domain)
+		if err != nil {
+			verr.add(fmt.Sprintf("%s[%d]", field, index), domain, invalidDomainRule(err))
+			continue
+		}
+		if _, found := seen[canonical]; found {
+			continue
+		}
+		seen[canonical] = struct{}{}
+		normalized = append(normalized, canonical)
+	}
+	return
+}
+
+func invalidDomainRule(err error) string {
+	return fmt.Sprintf("must be a valid domain name (%s)", err)
+}
+
+// validateDomains reports the invalid, conflicting (both included and excluded) and too numerous domains of sq into verr.
+// The valid ones are returned in their canonical form, without duplicates.
+func (sq SearchQuery) validateDomains(verr *ValidationError) (included, excluded []string) {
+	included = normalizeDomainList("include_domains", sq.IncludeDomains, verr)
+	excluded = normalizeDomainList("exclude_domains", sq.ExcludeDomains, verr)
+	if len(included) > SearchMaxIncludeDomains {
+		verr.add("include_domains", len(included), fmt.Sprintf("must contain at most %d distinct domains", SearchMaxIncludeDomains))
+	}
+	if len(excluded) > SearchMaxExcludeDomains {
+		verr.add("exclude_domains", len(excluded), fmt.Sprintf("must contain at most %d distinct domains", SearchMaxExcludeDomains))
+	}
+	for index, domain := range sq.ExcludeDomains {
+		canonical, err := NormalizeDomain(domain)
+		if err != nil {
+			continue // already reported
+		}
+		for _, includedDomain := range included {
+			if includedDomain == canonical {
+				verr.add(fmt.Sprintf("exclude_domains[%d]", index), domain, "must not also be in include_domains")
+				break
+			}
+		}
+	}
+	return
+}
+
+// NormalizeDomains returns a copy of the query with its included and excluded domains in their canonical form
+// (see NormalizeDomain) and without duplicates. The search methods of the client do it before sending the query.
+func (sq SearchQuery) NormalizeDomains() (normalized SearchQuery, err error) {
+	var verr ValidationError
+	normalized = sq
+	normalized.IncludeDomains = normalizeDomainList("include_domains", sq.IncludeDomains, &verr)
+	normalized.ExcludeDomains = normalizeDomainList("exclude_domains", sq.ExcludeDomains, &verr)
+	if err = verr.err(); err != nil {
+		return sq, err
+	}
+	return
+}
+
+// ParseDomainList reads a curated list of domains, one per line. Empty lines and comments (starting with "#") are ignored.
+// Domains are returned in their canonical form (see NormalizeDomain) and without duplicates.
+func ParseDomainList(r io.Reader) (domains []string, err error) {
+	var verr ValidationError
+	seen := make(map[string]struct{})
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if index := strings.Index(text, "#"); index >= 0 {
+			text = text[:index]
+		}
+		if text = strings.TrimSpace(text); text == "" {
+			continue
+		}
+		domain, err := NormalizeDomain(text)
+		if err != nil {
+			verr.add(fmt.Sprintf("line %d", line), text, invalidDomainRule(err))
+			continue
+		}
+		if _, found := seen[domain]; found {
+			continue
+		}
+		seen[domain] = struct{}{}
+		domains = append(domains, domain)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read domain list: %w", err)
+	}
+	if err = verr.err(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// LoadDomainList reads a curated list of domains from a file, see ParseDomainList.
+func LoadDomainList(path string) (domains []string, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open domain list: %w", err)
+	}
+	defer file.Close()
+	return ParseDomainList(file)
+}
@@ -0,0 +1,98 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeDomain(t *testing.T) {
+	for domain, expected := range map[string]string{
+		"example.com":                           "example.com",
+		" https://WWW.Example.com:443/news?a=b": "www.example.com",
+		"user:pass@example.com:8080":            "example.com",
+		"example.com/path#fragment":             "example.com",
+		"bücher.de":                             "xn--bcher-kva.de",
+	} {
+		if normalized, err := NormalizeDomain(domain); err != nil || normalized != expected {
+			t.Errorf("%q: expected %q, got %q (%v)", domain, expected, normalized, err)
+		}
+	}
+	for _, domain := range []string{"", "*.example.com", "www.*.com", "exa mple.com", "example..com"} {
+		if normalized, err := NormalizeDomain(domain); err == nil {
+			t.Errorf("%q: expected an error, got %q", domain, normalized)
+		}
+	}
+}
+
+func TestSearchQueryDomainsValidation(t *testing.T) {
+	query := SearchQuery{
+		Query:          "q",
+		IncludeDomains: []string{"Example.com", "*.news.com", "other.com"},
+		ExcludeDomains: []string{"https://example.com/"},
+	}
+	var verr ValidationError
+	if !errors.As(query.Validate(), &verr) {
+		t.Fatal("expected a validation error")
+	}
+	var fields []string
+	for _, field := range verr.Fields {
+		fields = append(fields, field.Field)
+	}
+	if !slices.Equal(fields, []string{"include_domains[1]", "exclude_domains[0]"}) {
+		t.Errorf("expected the wildcard and the conflicting domains to be reported, got %v", verr)
+	}
+	query.IncludeDomains = []string{"Example.com", "example.com", "other.com"}
+	query.ExcludeDomains = nil
+	normalized, err := query.NormalizeDomains()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(normalized.IncludeDomains, []string{"example.com", "other.com"}) || len(query.IncludeDomains) != 3 {
+		t.Errorf("unexpected normalized domains: %v", normalized.IncludeDomains)
+	}
+	query.IncludeDomains = make([]string, SearchMaxIncludeDomains+1)
+	for index := range query.IncludeDomains {
+		query.IncludeDomains[index] = strings.Repeat("a", index%50+1) + ".example" + strings.Repeat("b", index/50+1) + ".com"
+	}
+	if !errors.As(query.Validate(), &verr) || verr.Fields[0].Field != "include_domains" {
+		t.Errorf("expected the too long list to be reported, got %v", verr)
+	}
+}
+
+func TestSearchSendsNormalizedDomains(t *testing.T) {
+	api := &fakeAPI{}
+	root := newTestClient(t, api, ClientConfig{})
+	_, err := root.Search(context.Background(), SearchQuery{
+		Query:          "q",
+		IncludeDomains: []string{"https://Example.com/news", "example.com"},
+		ExcludeDomains: []string{"bücher.de"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := api.calls()[0].Payload
+	if included := payload["include_domains"].([]any); len(included) != 1 || included[0] != "example.com" {
+		t.Errorf("unexpected included domains sent: %v", included)
+	}
+	if excluded := payload["exclude_domains"].([]any); len(excluded) != 1 || excluded[0] != "xn--bcher-kva.de" {
+		t.Errorf("unexpected excluded domains sent: %v", excluded)
+	}
+}
+
+func TestParseDomainList(t *testing.T) {
+	domains, err := ParseDomainList(strings.NewReader("# curated\nexample.com\n\nEXAMPLE.com # duplicate\nhttps://news.org/feed\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(domains, []string{"example.com", "news.org"}) {
+		t.Errorf("unexpected domains: %v", domains)
+	}
+	_, err = ParseDomainList(strings.NewReader("example.com\n*.news.org\n"))
+	var verr ValidationError
+	if !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Field != "line 2" {
+		t.Errorf("expected the wildcard line to be reported, got %v", err)
+	}
+}
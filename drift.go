@@ -0,0 +1,191 @@
+package tavily
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SchemaDriftKind qualifies a difference between an API response and the types of this package.
+type SchemaDriftKind string
+
+const (
+	// SchemaDriftUnknownField is a field of the response not known by this package, preserved in the Extra map of its object.
+	SchemaDriftUnknownField SchemaDriftKind = "unknown_field"
+	// SchemaDriftTypeMismatch is a value of the response not matching the type of its field, which is left to its zero value.
+	SchemaDriftTypeMismatch SchemaDriftKind = "type_mismatch"
+)
+
+// SchemaDrift represents a difference between an API response and the types of this package.
+type SchemaDrift struct {
+	Endpoint string          // The endpoint of the response (see the Endpoint* constants).
+	Path     string          // The JSON path of the field within the response, eg "results[2].score".
+	Kind     SchemaDriftKind // The kind of difference.
+	Value    json.RawMessage // The raw value of the field.
+	Expected string          // The expected Go type, for type mismatches.
+}
+
+func (sd SchemaDrift) String() string {
+	if sd.Kind == SchemaDriftTypeMismatch {
+		return fmt.Sprintf("%s: %s expected %s got %s", sd.Path, sd.Kind, sd.Expected, sd.Value)
+	}
+	return fmt.Sprintf("%s: %s", sd.Path, sd.Kind)
+}
+
+// SchemaError is returned when decoding an answer with values not matching the types of their fields.
+// Every other field is decoded.
+type SchemaError struct {
+	Drifts []SchemaDrift
+}
+
+func (e *SchemaError) Error() string {
+	drifts := make([]string, len(e.Drifts))
+	for index, drift := range e.Drifts {
+		drifts[index] = drift.String()
+	}
+	return "response does not match the expected schema: " + strings.Join(drifts, ", ")
+}
+
+// unknownFieldsReporter is implemented by the answers able to report the unknown fields they have preserved.
+type unknownFieldsReporter interface {
+	unknownFields() []SchemaDrift
+}
+
+// objectDecoder decodes JSON objects field by field, keeping on decoding the other fields on type mismatches.
+type objectDecoder struct {
+	drifts []SchemaDrift
+}
+
+// fieldDecoder decodes a field value with a custom logic (see sliceField).
+type fieldDecoder func(od *objectDecoder, path string, value json.RawMessage) error
+
+// object decodes the JSON object data into fields (JSON name to pointer or fieldDecoder) and returns the unknown ones.
+func (od *objectDecoder) object(data []byte, fields map[string]any) (extra map[string]json.RawMessage, err error) {
+	var raw map[string]json.RawMessage
+	if err = od.value("", data, &raw); err != nil || raw == nil {
+		return
+	}
+	for name, value := range raw {
+		target, known := fields[name]
+		if !known {
+			if extra == nil {
+				extra = make(map[string]json.RawMessage)
+			}
+			extra[name] = value
+			continue
+		}
+		if decoder, ok := target.(fieldDecoder); ok {
+			err = decoder(od, name, value)
+		} else {
+			err = od.value(name, value, target)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode field %q: %w", name, err)
+		}
+	}
+	return
+}
+
+// value decodes value into target, recording type mismatches (including the nested ones) as drifts.
+func (od *objectDecoder) value(path string, value json.RawMessage, target any) error {
+	err := json.Unmarshal(value, target)
+	var (
+		schemaErr *SchemaError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &schemaErr):
+		for _, drift := range schemaErr.Drifts {
+			drift.Path = joinJSONPath(path, drift.Path)
+			od.drifts = append(od.drifts, drift)
+		}
+		return nil
+	case errors.As(err, &typeErr):
+		expected := typeErr.Value
+		if typeErr.Type != nil {
+			expected = typeErr.Type.String()
+		}
+		od.drifts = append(od.drifts, SchemaDrift{
+			Path:     joinJSONPath(path, typeErr.Field),
+			Kind:     SchemaDriftTypeMismatch,
+			Value:    value,
+			Expected: expected,
+		})
+		return nil
+	default:
+		return err
+	}
+}
+
+// err returns the type mismatches encountered as a SchemaError, nil if none.
+func (od *objectDecoder) err() error {
+	if len(od.drifts) == 0 {
+		return nil
+	}
+	return &SchemaError{Drifts: od.drifts}
+}
+
+// sliceField returns a decoder of a JSON array decoding each element on its own, so a type mismatch within an element
+// does not prevent the next ones to be decoded.
+func sliceField[T any](target *[]T) fieldDecoder {
+	return func(od *objectDecoder, path string, value json.RawMessage) (err error) {
+		var elements []json.RawMessage
+		if err = od.value(path, value, &elements); err != nil || elements == nil {
+			return
+		}
+		*target = make([]T, len(elements))
+		for index, element := range elements {
+			if err = od.value(fmt.Sprintf("%s[%d]", path, index), element, &(*target)[index]); err != nil {
+				return
+			}
+		}
+		return
+	}
+}
+
+func joinJSONPath(parent, child string) string {
+	switch {
+	case parent == "":
+		return child
+	case child == "":
+		return parent
+	case strings.HasPrefix(child, "["):
+		return parent + child
+	default:
+		return parent + "." + child
+	}
+}
+
+// extraDrifts returns the unknown fields of extra as drifts, sorted by name.
+func extraDrifts(path string, extra map[string]json.RawMessage) (drifts []SchemaDrift) {
+	for name, value := range extra {
+		drifts = append(drifts, SchemaDrift{
+			Path:  joinJSONPath(path, name),
+			Kind:  SchemaDriftUnknownField,
+			Value: value,
+		})
+	}
+	slices.SortFunc(drifts, func(a, b SchemaDrift) int { return strings.Compare(a.Path, b.Path) })
+	return
+}
+
+// marshalWithExtra marshals v and adds the unknown fields of extra to the resulting JSON object.
+func marshalWithExtra(v any, extra map[string]json.RawMessage) (data []byte, err error) {
+	if data, err = json.Marshal(v); err != nil || len(extra) == 0 {
+		return
+	}
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON object: %w", err)
+	}
+	for name, value := range extra {
+		if _, found := fields[name]; !found {
+			fields[name] = value
+		}
+	}
+	return json.Marshal(fields)
+}
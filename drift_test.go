@@ -0,0 +1,84 @@
+package tavily
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const driftingSearchAnswer = `{"query":"q","answer":null,"images":[],"results":[` +
+	`{"title":"t","url":"https://example.com/","content":"c","score":"high","favicon":"https://example.com/favicon.ico"}],` +
+	`"response_time":0.1,"usage":{"credits":1}}`
+
+func driftingAPI() *fakeAPI {
+	return &fakeAPI{handler: func(req *http.Request, payload map[string]any) *http.Response {
+		if req.URL.Path == "/"+EndpointSearch {
+			return jsonResponse(http.StatusOK, driftingSearchAnswer)
+		}
+		return defaultFakeHandler(req, payload)
+	}}
+}
+
+func TestSchemaDriftStrictMode(t *testing.T) {
+	var (
+		access sync.Mutex
+		drifts []string
+	)
+	root := newTestClient(t, driftingAPI(), ClientConfig{OnSchemaDrift: func(drift SchemaDrift) {
+		access.Lock()
+		defer access.Unlock()
+		if drift.Endpoint != EndpointSearch {
+			t.Errorf("unexpected drift endpoint: %+v", drift)
+		}
+		drifts = append(drifts, drift.String())
+	}})
+	// the type mismatch becomes a warning: every other field is decoded
+	answer := testSearch(t, root)
+	if len(answer.Results) != 1 || answer.Results[0].Title != "t" || answer.Results[0].Score != 0 {
+		t.Errorf("unexpected answer: %+v", answer)
+	}
+	slices.Sort(drifts)
+	expected := []string{
+		"results[0].favicon: unknown_field",
+		`results[0].score: type_mismatch expected float64 got "high"`,
+		"usage: unknown_field",
+	}
+	if !slices.Equal(drifts, expected) {
+		t.Errorf("unexpected drifts:\n%s", strings.Join(drifts, "\n"))
+	}
+}
+
+func TestSchemaDriftWithoutStrictMode(t *testing.T) {
+	root := newTestClient(t, driftingAPI(), ClientConfig{})
+	_, err := root.Search(context.Background(), SearchQuery{Query: "q"})
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) || len(schemaErr.Drifts) != 1 || schemaErr.Drifts[0].Path != "results[0].score" {
+		t.Fatalf("expected the type mismatch to fail the call, got %v", err)
+	}
+	var decodeErr DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Errorf("expected a DecodeError, got %v", err)
+	}
+}
+
+func TestUnknownFieldsPreserved(t *testing.T) {
+	var answer SearchAnswer
+	if err := json.Unmarshal([]byte(strings.Replace(driftingSearchAnswer, `"high"`, "0.5", 1)), &answer); err != nil {
+		t.Fatal(err)
+	}
+	if string(answer.Extra["usage"]) != `{"credits":1}` || string(answer.Results[0].Extra["favicon"]) != `"https://example.com/favicon.ico"` {
+		t.Fatalf("expected the unknown fields to be preserved, got %v and %v", answer.Extra, answer.Results[0].Extra)
+	}
+	data, err := json.Marshal(answer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"usage":{"credits":1}`) || !strings.Contains(string(data), `"favicon":"https://example.com/favicon.ico"`) {
+		t.Errorf("expected the unknown fields to be marshaled back: %s", data)
+	}
+}
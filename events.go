@@ -0,0 +1,126 @@
+package tavily
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// UsageEvent describes a single operation performed thru a session, billed or not.
+type UsageEvent struct {
+	Time        time.Time         // When the operation ended.
+	Operation   string            // EndpointSearch or EndpointExtract.
+	Depth       string            // "basic" or "advanced".
+	Usage       Stats             // The usage of the operation, empty if it failed.
+	Credits     float64           // The API credits consumed by the operation.
+	SessionPath []string          // Names of the sessions from the root session to the one the operation has been made on.
+	Labels      map[string]string // Labels of the session the operation has been made on, including the ones inherited from its parents.
+	Tags        map[string]string // Cost attribution tags carried by the context of the operation (see WithTags).
+	Latency     time.Duration     // Total duration of the operation, including the time spent waiting for the limiters.
+	Err         error             // Why the operation failed, if it did.
+}
+
+// publishUsage delivers event to the subscribers of the session and the ones of its parents.
+func (s *Session) publishUsage(event UsageEvent) {
+	for current := s; current != nil; current = current.parent {
+		current.subscribers.publish(event)
+	}
+}
+
+// inheritedLabels returns the labels of the session merged with the ones of its parents (closest wins).
+func (s *Session) inheritedLabels() (labels map[string]string) {
+	if s.parent != nil {
+		labels = s.parent.inheritedLabels()
+	}
+	if labels == nil {
+		labels = make(map[string]string, len(s.labels))
+	}
+	maps.Copy(labels, s.labels)
+	return
+}
+
+// OnUsage registers a callback called for each operation made on this session or its sub sessions.
+// The callback is called synchronously by the goroutine which made the operation: it must be fast and not block.
+// The returned function unregisters the callback.
+func (s *Session) OnUsage(callback func(UsageEvent)) (unregister func()) {
+	return s.subscribers.add(callback)
+}
+
+// SubscribeUsage returns a channel receiving the events of each operation made on this session or its sub sessions.
+// The delivery never blocks the operations: events are dropped when the channel buffer is full.
+// The returned function unsubscribes and closes the channel.
+func (s *Session) SubscribeUsage(bufferSize int) (events <-chan UsageEvent, unsubscribe func()) {
+	subscription := &usageChannel{
+		events: make(chan UsageEvent, max(bufferSize, 0)),
+	}
+	unregister := s.subscribers.add(subscription.send)
+	return subscription.events, func() {
+		unregister()
+		subscription.close()
+	}
+}
+
+type usageSubscribers struct {
+	access    sync.RWMutex
+	nextID    uint64
+	callbacks map[uint64]func(UsageEvent)
+}
+
+func (us *usageSubscribers) add(callback func(UsageEvent)) (remove func()) {
+	us.access.Lock()
+	defer us.access.Unlock()
+	if us.callbacks == nil {
+		us.callbacks = make(map[uint64]func(UsageEvent))
+	}
+	id := us.nextID
+	us.nextID++
+	us.callbacks[id] = callback
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			us.access.Lock()
+			defer us.access.Unlock()
+			delete(us.callbacks, id)
+		})
+	}
+}
+
+func (us *usageSubscribers) publish(event UsageEvent) {
+	// callbacks are called without the lock held, allowing them to unregister themselves
+	us.access.RLock()
+	callbacks := slices.Collect(maps.Values(us.callbacks))
+	us.access.RUnlock()
+	for _, callback := range callbacks {
+		callback(event)
+	}
+}
+
+// usageChannel delivers events to a channel without blocking.
+type usageChannel struct {
+	access sync.Mutex
+	events chan UsageEvent
+	closed bool
+}
+
+func (uc *usageChannel) send(event UsageEvent) {
+	uc.access.Lock()
+	defer uc.access.Unlock()
+	if uc.closed {
+		return
+	}
+	select {
+	case uc.events <- event:
+	default:
+		// subscriber is too slow, drop the event
+	}
+}
+
+func (uc *usageChannel) close() {
+	uc.access.Lock()
+	defer uc.access.Unlock()
+	if !uc.closed {
+		uc.closed = true
+		close(uc.events)
+	}
+}
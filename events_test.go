@@ -0,0 +1,86 @@
+package tavily
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestUsageEvents(t *testing.T) {
+	root := newTestClient(t, &fakeAPI{}, ClientConfig{})
+	tenant, err := root.NewSessionWithConfig(SessionConfig{Name: "tenant", Labels: map[string]string{"tenant": "a", "plan": "free"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conversation, err := tenant.NewSessionWithConfig(SessionConfig{Name: "conv", Labels: map[string]string{"plan": "pro"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rootEvents, tenantEvents []UsageEvent
+	unregister := root.OnUsage(func(event UsageEvent) { rootEvents = append(rootEvents, event) })
+	tenant.OnUsage(func(event UsageEvent) { tenantEvents = append(tenantEvents, event) })
+	ctx := WithTag(context.Background(), "user_id", "42")
+	if _, err = conversation.Search(ctx, SearchQuery{Query: "q", SearchDepth: SearchQueryDepthAdvanced}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rootEvents) != 1 || len(tenantEvents) != 1 {
+		t.Fatalf("expected each ancestor to receive the event once, got %d and %d", len(rootEvents), len(tenantEvents))
+	}
+	event := rootEvents[0]
+	if event.Operation != EndpointSearch || event.Depth != "advanced" || event.Credits != 2 || event.Usage.AdvancedSearches != 1 {
+		t.Errorf("unexpected operation: %+v", event)
+	}
+	if !slices.Equal(event.SessionPath, []string{"", "tenant", "conv"}) {
+		t.Errorf("unexpected session path: %v", event.SessionPath)
+	}
+	if event.Labels["tenant"] != "a" || event.Labels["plan"] != "pro" {
+		t.Errorf("expected inherited labels with the closest winning, got %v", event.Labels)
+	}
+	if event.Tags["user_id"] != "42" {
+		t.Errorf("expected the context tags, got %v", event.Tags)
+	}
+	// events of sibling sessions do not reach unrelated sessions, unregistered callbacks are not called
+	unregister()
+	testSearch(t, root)
+	if len(rootEvents) != 1 || len(tenantEvents) != 1 {
+		t.Fatalf("unexpected events delivery: %d and %d", len(rootEvents), len(tenantEvents))
+	}
+}
+
+func TestUsageEventOnFailure(t *testing.T) {
+	api := &fakeAPI{handler: func(*http.Request, map[string]any) *http.Response {
+		return jsonResponse(http.StatusBadRequest, `{"detail":{"error":"bad request"}}`)
+	}}
+	root := newTestClient(t, api, ClientConfig{})
+	var events []UsageEvent
+	root.OnUsage(func(event UsageEvent) { events = append(events, event) })
+	if _, err := root.Extract(context.Background(), ExtractRequest{URLs: []string{"https://example.com"}}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(events) != 1 || events[0].Err == nil || events[0].Credits != 0 || events[0].Depth != "basic" {
+		t.Fatalf("unexpected failure event: %+v", events)
+	}
+}
+
+func TestSubscribeUsage(t *testing.T) {
+	root := newTestClient(t, &fakeAPI{}, ClientConfig{})
+	events, unsubscribe := root.SubscribeUsage(1)
+	testSearch(t, root)
+	// the buffer is full: the event is dropped instead of blocking the operation
+	testSearch(t, root)
+	if event := <-events; event.Operation != EndpointSearch {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+	select {
+	case event := <-events:
+		t.Fatalf("expected the second event to be dropped, got %+v", event)
+	default:
+	}
+	unsubscribe()
+	unsubscribe()
+	if _, open := <-events; open {
+		t.Fatal("expected the channel to be closed")
+	}
+	testSearch(t, root)
+}
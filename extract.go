@@ -6,6 +6,13 @@ import (
 	"fmt"
 	"net/url"
 	"time"
+
+	"github.com/hekmon/tavily/v2/urlcanon"
+)
+
+const (
+	// ExtractMaxURLs is the maximum number of URLs an extract request can contain, see https://docs.tavily.com/api-reference/endpoint/extract#body-urls
+	ExtractMaxURLs = 20
 )
 
 type ExtractRequestDepth string
@@ -16,24 +23,76 @@ const (
 )
 
 type ExtractRequest struct {
-	URLs          []string            `json:"urls"`
-	IncludeImages bool                `json:"include_images,omitempty"`
-	ExtractDepth  ExtractRequestDepth `json:"extract_depth,omitempty"`
+	URLs          []string            `json:"urls"`                     // The URLs of the web pages to extract content from.
+	IncludeImages bool                `json:"include_images,omitempty"` // Include a list of images extracted from the URLs in the response. Default is false.
+	ExtractDepth  ExtractRequestDepth `json:"extract_depth,omitempty"`  // The depth of the extraction process. Advanced extraction retrieves more data (tables, embedded content) but costs more credits. Default is "basic".
+}
+
+// Deduplicate returns a copy of the request without the URLs sharing the same canonical form (see the urlcanon package).
+// The first occurrence of each URL is kept as is. The client always deduplicates the requests it sends, avoiding to pay twice
+// for the same page.
+func (er ExtractRequest) Deduplicate() ExtractRequest {
+	seen := make(map[string]struct{}, len(er.URLs))
+	urls := make([]string, 0, len(er.URLs))
+	for _, u := range er.URLs {
+		key := urlcanon.Key(u)
+		if _, found := seen[key]; found {
+			continue
+		}
+		seen[key] = struct{}{}
+		urls = append(urls, u)
+	}
+	er.URLs = urls
+	return er
+}
+
+// Validate checks the request and returns a ValidationError listing every invalid field.
+func (er ExtractRequest) Validate() error {
+	var verr ValidationError
+	if len(er.URLs) > ExtractMaxURLs {
+		verr.add("urls", len(er.URLs), fmt.Sprintf("must contain at most %d URLs", ExtractMaxURLs))
+	}
+	er.validate(&verr)
+	return verr.err()
+}
+
+// validate reports the invalid fields of er into verr, whatever its number of URLs.
+func (er ExtractRequest) validate(verr *ValidationError) {
+	// URLs
+	if len(er.URLs) == 0 {
+		verr.add("urls", er.URLs, "must contain at least one URL")
+	}
+	for index, u := range er.URLs {
+		if _, err := url.ParseRequestURI(u); err != nil {
+			verr.add(fmt.Sprintf("urls[%d]", index), u, "must be a valid absolute URL")
+		}
+	}
+	// Extract Depth
+	switch er.ExtractDepth {
+	case ExtractRequestDepthBasic, ExtractRequestDepthAdvanced, "":
+	default:
+		verr.add("extract_depth", er.ExtractDepth, enumRule(ExtractRequestDepthBasic, ExtractRequestDepthAdvanced))
+	}
 }
 
 // Extract web page content from one or more specified URLs using Tavily Extract.
 // See https://docs.tavily.com/api-reference/endpoint/extract for more infos.
 func (c *mainClient) Extract(ctx context.Context, request ExtractRequest) (answer ExtractAnswer, err error) {
-	// Validate URLs
-	for _, u := range request.URLs {
-		if _, err := url.ParseRequestURI(u); err != nil {
-			return answer, fmt.Errorf("invalid URL %q: %w", u, err)
-		}
+	// Validate request
+	if err = request.Validate(); err != nil {
+		err = fmt.Errorf("failed to validate extract request: %w", err)
+		return
 	}
+	request = request.Deduplicate()
 	// Execute
-	if err = c.request(ctx, "extract", request, &answer); err != nil {
+	key, err := c.request(ctx, EndpointExtract, request, &answer)
+	if err != nil {
 		err = fmt.Errorf("failed to execute API query: %w", err)
+		return
 	}
+	responseMetaFromContext(ctx).setRequestID(answer.RequestID)
+	// Credits accounting
+	key.addCredits(extractUsage(request, answer).TotalCost())
 	return
 }
 
@@ -41,21 +100,26 @@ type ExtractAnswer struct {
 	Results       []ExtractAnswerResult       `json:"results"`
 	FailedResults []ExtractAnswerFailedResult `json:"failed_results"`
 	ResponseTime  time.Duration               `json:"-"`
+	RequestID     string                      `json:"request_id,omitempty"`
+	Raw           json.RawMessage             `json:"-"` // The raw JSON response, only set if the client keeps raw responses.
+	Extra         map[string]json.RawMessage  `json:"-"` // Fields of the response not known by this package.
 }
 
 func (ea *ExtractAnswer) UnmarshalJSON(data []byte) (err error) {
-	type mask ExtractAnswer
-	tmp := struct {
-		*mask
-		ResponseTime float64 `json:"response_time"`
-	}{
-		mask: (*mask)(ea),
-	}
-	if err = json.Unmarshal(data, &tmp); err != nil {
-		return fmt.Errorf("failed to unmarshal JSON into tmp struct: %w", err)
+	var (
+		od           objectDecoder
+		responseTime float64
+	)
+	if ea.Extra, err = od.object(data, map[string]any{
+		"results":        sliceField(&ea.Results),
+		"failed_results": sliceField(&ea.FailedResults),
+		"response_time":  &responseTime,
+		"request_id":     &ea.RequestID,
+	}); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON object: %w", err)
 	}
-	ea.ResponseTime = time.Duration(tmp.ResponseTime * float64(time.Second))
-	return
+	ea.ResponseTime = time.Duration(responseTime * float64(time.Second))
+	return od.err()
 }
 
 func (ea ExtractAnswer) MarshalJSON() ([]byte, error) {
@@ -67,29 +131,45 @@ func (ea ExtractAnswer) MarshalJSON() ([]byte, error) {
 		mask:         mask(ea),
 		ResponseTime: ea.ResponseTime.Seconds(),
 	}
-	return json.Marshal(tmp)
+	return marshalWithExtra(tmp, ea.Extra)
+}
+
+func (ea *ExtractAnswer) setRaw(json []byte) {
+	ea.Raw = json
+}
+
+func (ea *ExtractAnswer) unknownFields() (drifts []SchemaDrift) {
+	drifts = extraDrifts("", ea.Extra)
+	for index, result := range ea.Results {
+		drifts = append(drifts, extraDrifts(fmt.Sprintf("results[%d]", index), result.Extra)...)
+	}
+	for index, failed := range ea.FailedResults {
+		drifts = append(drifts, extraDrifts(fmt.Sprintf("failed_results[%d]", index), failed.Extra)...)
+	}
+	return
 }
 
 type ExtractAnswerResult struct {
-	URL        *url.URL `json:"-"`
-	RawContent string   `json:"raw_content"`
+	URL        *url.URL                   `json:"-"`
+	RawContent string                     `json:"raw_content"`
+	Extra      map[string]json.RawMessage `json:"-"` // Fields of the response not known by this package.
 }
 
 func (ear *ExtractAnswerResult) UnmarshalJSON(data []byte) (err error) {
-	type mask ExtractAnswerResult
-	tmp := struct {
-		URL string `json:"url"`
-		*mask
-	}{
-		mask: (*mask)(ear),
-	}
-	if err = json.Unmarshal(data, &tmp); err != nil {
-		return fmt.Errorf("failed to unmarshal JSON into tmp struct: %w", err)
+	var (
+		od     objectDecoder
+		rawURL string
+	)
+	if ear.Extra, err = od.object(data, map[string]any{
+		"url":         &rawURL,
+		"raw_content": &ear.RawContent,
+	}); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON object: %w", err)
 	}
-	if ear.URL, err = url.Parse(tmp.URL); err != nil {
+	if ear.URL, err = url.Parse(rawURL); err != nil {
 		return fmt.Errorf("failed to parse URL: %w", err)
 	}
-	return
+	return od.err()
 }
 
 func (ear ExtractAnswerResult) MarshalJSON() ([]byte, error) {
@@ -101,10 +181,27 @@ func (ear ExtractAnswerResult) MarshalJSON() ([]byte, error) {
 		URL:  ear.URL.String(),
 		mask: mask(ear),
 	}
-	return json.Marshal(tmp)
+	return marshalWithExtra(tmp, ear.Extra)
 }
 
 type ExtractAnswerFailedResult struct {
-	URL    string `json:"url"` // can be invalid, can not use url.URL here
-	Reason string `json:"reason"`
+	URL    string                     `json:"url"` // can be invalid, can not use url.URL here
+	Reason string                     `json:"reason"`
+	Extra  map[string]json.RawMessage `json:"-"` // Fields of the response not known by this package.
+}
+
+func (eafr *ExtractAnswerFailedResult) UnmarshalJSON(data []byte) (err error) {
+	var od objectDecoder
+	if eafr.Extra, err = od.object(data, map[string]any{
+		"url":    &eafr.URL,
+		"reason": &eafr.Reason,
+	}); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON object: %w", err)
+	}
+	return od.err()
+}
+
+func (eafr ExtractAnswerFailedResult) MarshalJSON() ([]byte, error) {
+	type mask ExtractAnswerFailedResult
+	return marshalWithExtra(mask(eafr), eafr.Extra)
 }
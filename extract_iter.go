@@ -0,0 +1,156 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/url"
+	"sync"
+)
+
+// ExtractIterConfig configures the batches of ExtractIter.
+type ExtractIterConfig struct {
+	// Optional number of URLs per extract request, within [1, ExtractMaxURLs]. Default is ExtractMaxURLs.
+	BatchSize int
+	// Optional number of extract requests executed concurrently (still subject to the client limits). Default is 1.
+	Parallelism int
+}
+
+// ExtractFailedError is yielded by ExtractIter for a URL the API failed to extract.
+type ExtractFailedError struct {
+	URL    string
+	Reason string
+}
+
+func (e ExtractFailedError) Error() string {
+	return fmt.Sprintf("failed to extract %q: %s", e.URL, e.Reason)
+}
+
+// ExtractBatchError is yielded by ExtractIter when an extract request of a batch of URLs fails.
+type ExtractBatchError struct {
+	URLs []string
+	Err  error
+}
+
+func (e ExtractBatchError) Error() string {
+	return fmt.Sprintf("failed to extract a batch of %d URLs: %s", len(e.URLs), e.Err)
+}
+
+func (e ExtractBatchError) Unwrap() error {
+	return e.Err
+}
+
+// ExtractIter splits the URLs of request into batches, each one being a regular Extract call of client, and yields
+// each URL result as soon as its batch completes, allowing to process them while the next batches are downloading.
+// Results are yielded in completion order. URLs sharing the same canonical form are extracted once. A URL that can not be
+// extracted yields an ExtractFailedError (with a result only carrying its URL if valid) and a failed batch yields an
+// ExtractBatchError, the iteration goes on with the other batches.
+// Stopping the iteration cancels the pending requests. If ctx is done before the end, the batches not extracted yet
+// yield an ExtractBatchError carrying its error. A ResponseMeta carried by ctx is ignored (see WithResponseMeta).
+func ExtractIter(ctx context.Context, client Client, request ExtractRequest, conf ExtractIterConfig) iter.Seq2[ExtractAnswerResult, error] {
+	return func(yield func(ExtractAnswerResult, error) bool) {
+		// Configuration
+		switch {
+		case conf.BatchSize < 0 || conf.BatchSize > ExtractMaxURLs:
+			yield(ExtractAnswerResult{}, fmt.Errorf("batch size must be within [1, %d]", ExtractMaxURLs))
+			return
+		case conf.BatchSize == 0:
+			conf.BatchSize = ExtractMaxURLs
+		}
+		switch {
+		case conf.Parallelism < 0:
+			yield(ExtractAnswerResult{}, errors.New("parallelism must be a non-negative integer"))
+			return
+		case conf.Parallelism == 0:
+			conf.Parallelism = 1
+		}
+		var verr ValidationError
+		request.validate(&verr) // any number of URLs: they are split into batches
+		if err := verr.err(); err != nil {
+			yield(ExtractAnswerResult{}, fmt.Errorf("failed to validate extract request: %w", err))
+			return
+		}
+		request = request.Deduplicate()
+		// Split into batches
+		batches := make(chan ExtractRequest, (len(request.URLs)+conf.BatchSize-1)/conf.BatchSize)
+		for start := 0; start < len(request.URLs); start += conf.BatchSize {
+			batch := request
+			batch.URLs = request.URLs[start:min(start+conf.BatchSize, len(request.URLs))]
+			batches <- batch
+		}
+		close(batches)
+		// Extract them
+		ctx = withoutResponseMeta(ctx) // shared by the batches
+		callCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stop := make(chan struct{}) // closed when the iteration is stopped: results are not needed anymore
+		done := make(chan extractBatchResult)
+		var workers sync.WaitGroup
+		for range min(conf.Parallelism, len(batches)) {
+			workers.Add(1)
+			go func() {
+				defer workers.Done()
+				for batch := range batches {
+					result := extractBatchResult{urls: batch.URLs}
+					if result.err = ctx.Err(); result.err == nil {
+						result.answer, result.err = client.Extract(callCtx, batch)
+					}
+					select {
+					case done <- result:
+					case <-stop:
+						return
+					}
+				}
+			}()
+		}
+		go func() {
+			workers.Wait()
+			close(done)
+		}()
+		defer func() {
+			// unblock and wait for the workers if the iteration has been stopped
+			close(stop)
+			cancel()
+			for range done {
+			}
+		}()
+		for result := range done {
+			if !result.yield(yield) {
+				return
+			}
+		}
+	}
+}
+
+type extractBatchResult struct {
+	urls   []string
+	answer ExtractAnswer
+	err    error
+}
+
+// yield yields the results of the batch, returns false if the iteration has been stopped.
+func (ebr extractBatchResult) yield(yield func(ExtractAnswerResult, error) bool) bool {
+	if ebr.err != nil {
+		return yield(ExtractAnswerResult{}, ExtractBatchError{
+			URLs: ebr.urls,
+			Err:  ebr.err,
+		})
+	}
+	for _, result := range ebr.answer.Results {
+		if !yield(result, nil) {
+			return false
+		}
+	}
+	for _, failed := range ebr.answer.FailedResults {
+		result := ExtractAnswerResult{}
+		result.URL, _ = url.Parse(failed.URL)
+		if !yield(result, ExtractFailedError{
+			URL:    failed.URL,
+			Reason: failed.Reason,
+		}) {
+			return false
+		}
+	}
+	return true
+}
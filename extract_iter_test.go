@@ -0,0 +1,143 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExtractIter(t *testing.T) {
+	api := &fakeAPI{handler: func(req *http.Request, payload map[string]any) *http.Response {
+		urls := payload["urls"].([]any)
+		switch urls[0] {
+		case "https://example.com/3":
+			return jsonResponse(http.StatusInternalServerError, `{"detail":{"error":"internal error"}}`)
+		case "https://example.com/5":
+			return jsonResponse(http.StatusOK, `{"results":[],"failed_results":[{"url":"https://example.com/5","reason":"timeout"}],"response_time":0.1}`)
+		}
+		return defaultFakeHandler(req, payload)
+	}}
+	root := newTestClient(t, api, ClientConfig{})
+	request := ExtractRequest{URLs: []string{
+		"https://example.com/1", "https://example.com/2", "https://EXAMPLE.com/1", "https://example.com/3", "https://example.com/4", "https://example.com/5",
+	}}
+	var (
+		extracted    []string
+		failed       []string
+		batchFailure []string
+	)
+	for result, err := range ExtractIter(context.Background(), root, request, ExtractIterConfig{BatchSize: 2, Parallelism: 2}) {
+		var (
+			failedErr ExtractFailedError
+			batchErr  ExtractBatchError
+		)
+		switch {
+		case errors.As(err, &failedErr):
+			failed = append(failed, failedErr.URL+": "+failedErr.Reason)
+		case errors.As(err, &batchErr):
+			batchFailure = append(batchFailure, batchErr.URLs...)
+		case err != nil:
+			t.Fatal(err)
+		default:
+			extracted = append(extracted, result.URL.String())
+		}
+	}
+	slices.Sort(extracted)
+	if !slices.Equal(extracted, []string{"https://example.com/1", "https://example.com/2"}) {
+		t.Errorf("unexpected extracted URLs: %v", extracted)
+	}
+	if !slices.Equal(failed, []string{"https://example.com/5: timeout"}) {
+		t.Errorf("unexpected failed URLs: %v", failed)
+	}
+	if !slices.Equal(batchFailure, []string{"https://example.com/3", "https://example.com/4"}) {
+		t.Errorf("unexpected failed batch: %v", batchFailure)
+	}
+	if calls := api.calls(); len(calls) != 3 {
+		t.Errorf("expected the deduplicated URLs to be extracted in 3 batches, got %d calls", len(calls))
+	}
+}
+
+func TestExtractIterContextCanceled(t *testing.T) {
+	api := &fakeAPI{handler: func(req *http.Request, payload map[string]any) *http.Response {
+		if payload["urls"].([]any)[0] != "https://example.com/1" {
+			<-req.Context().Done()
+			return jsonResponse(http.StatusServiceUnavailable, `{"detail":{"error":"unavailable"}}`)
+		}
+		return defaultFakeHandler(req, payload)
+	}}
+	root := newTestClient(t, api, ClientConfig{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	request := ExtractRequest{URLs: []string{"https://example.com/1", "https://example.com/2", "https://example.com/3"}}
+	var (
+		results int
+		errs    []error
+	)
+	for _, err := range ExtractIter(ctx, root, request, ExtractIterConfig{BatchSize: 1}) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		results++
+		cancel()
+	}
+	// the caller must be able to tell the extraction has been truncated
+	if results != 1 || len(errs) != 2 {
+		t.Fatalf("expected 1 result and 2 batch errors, got %d and %v", results, errs)
+	}
+	for _, err := range errs {
+		var batchErr ExtractBatchError
+		if !errors.As(err, &batchErr) || len(batchErr.URLs) != 1 {
+			t.Errorf("expected a batch error, got %v", err)
+		}
+	}
+	if last := errs[1]; !errors.Is(last, context.Canceled) || !strings.Contains(last.Error(), "1 URLs") {
+		t.Errorf("expected the batch never started to carry the context error, got %v", last)
+	}
+}
+
+func TestExtractIterStopped(t *testing.T) {
+	api := &fakeAPI{}
+	root := newTestClient(t, api, ClientConfig{})
+	request := ExtractRequest{URLs: []string{"https://example.com/1", "https://example.com/2", "https://example.com/3", "https://example.com/4"}}
+	var yielded int
+	for _, err := range ExtractIter(context.Background(), root, request, ExtractIterConfig{BatchSize: 1, Parallelism: 2}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		yielded++
+		break
+	}
+	if yielded != 1 {
+		t.Errorf("expected the iteration to stop after the first result, got %d", yielded)
+	}
+	for _, err := range ExtractIter(context.Background(), root, request, ExtractIterConfig{BatchSize: ExtractMaxURLs + 1}) {
+		if err == nil || !strings.Contains(err.Error(), "batch size") {
+			t.Errorf("expected a configuration error, got %v", err)
+		}
+	}
+}
+
+func TestExtractIterLargeRequest(t *testing.T) {
+	api := &fakeAPI{}
+	root := newTestClient(t, api, ClientConfig{})
+	// more URLs than a single extract request can contain
+	request := ExtractRequest{URLs: make([]string, ExtractMaxURLs+1)}
+	for index := range request.URLs {
+		request.URLs[index] = fmt.Sprintf("https://example.com/%d", index)
+	}
+	var yielded int
+	for _, err := range ExtractIter(context.Background(), root, request, ExtractIterConfig{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		yielded++
+	}
+	if yielded != len(request.URLs) || len(api.calls()) != 2 {
+		t.Errorf("expected %d results from 2 batches, got %d from %d", len(request.URLs), yielded, len(api.calls()))
+	}
+}
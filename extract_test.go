@@ -0,0 +1,64 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestExtractRequestDeduplicate(t *testing.T) {
+	request := ExtractRequest{URLs: []string{
+		"https://example.com/a",
+		"http://EXAMPLE.com/a/?utm_source=x",
+		"https://example.com/b",
+		"https://example.com/a#top",
+	}}
+	deduplicated := request.Deduplicate()
+	if !slices.Equal(deduplicated.URLs, []string{"https://example.com/a", "https://example.com/b"}) {
+		t.Fatalf("unexpected URLs: %v", deduplicated.URLs)
+	}
+	if len(request.URLs) != 4 {
+		t.Fatal("original request has been modified")
+	}
+}
+
+func TestExtractSendsDeduplicatedURLs(t *testing.T) {
+	api := &fakeAPI{}
+	client := newTestClient(t, api, ClientConfig{})
+	answer, err := client.Extract(context.Background(), ExtractRequest{URLs: []string{
+		"https://example.com/a",
+		"https://example.com/a/",
+		"https://example.com/b",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(answer.Results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(answer.Results))
+	}
+	if urls := api.calls()[0].Payload["urls"].([]any); len(urls) != 2 {
+		t.Fatalf("expected 2 URLs sent, got %v", urls)
+	}
+	if got := client.Stats().BasicExtracts; got != 2 {
+		t.Fatalf("expected 2 billed extracts, got %d", got)
+	}
+}
+
+func TestExtractMaxURLs(t *testing.T) {
+	api := &fakeAPI{}
+	root := newTestClient(t, api, ClientConfig{})
+	request := ExtractRequest{URLs: make([]string, ExtractMaxURLs+1)}
+	for index := range request.URLs {
+		request.URLs[index] = fmt.Sprintf("https://example.com/%d", index)
+	}
+	_, err := root.Extract(context.Background(), request)
+	var verr ValidationError
+	if !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Field != "urls" {
+		t.Fatalf("expected the too long list to be reported, got %v", err)
+	}
+	if len(api.calls()) != 0 {
+		t.Error("expected the invalid request not to be sent")
+	}
+}
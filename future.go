@@ -0,0 +1,123 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Future is the pending result of an asynchronous call (see SearchAsync and ExtractAsync).
+type Future[T any] struct {
+	done     chan struct{}
+	cancel   context.CancelFunc
+	value    T
+	err      error
+	meta     ResponseMeta  // captured by the call itself
+	metaDest *ResponseMeta // carried by the context of the call, filled by Wait
+}
+
+// newFuture executes fn in the background with a cancelable copy of ctx. As ctx may be shared by other futures,
+// the call captures its metadata on its own and a ResponseMeta carried by ctx is only filled by Wait.
+func newFuture[T any](ctx context.Context, fn func(context.Context) (T, error)) *Future[T] {
+	f := &Future[T]{
+		done:     make(chan struct{}),
+		metaDest: responseMetaFromContext(ctx),
+	}
+	if f.metaDest != nil {
+		ctx = WithResponseMeta(ctx, &f.meta)
+	}
+	ctx, f.cancel = context.WithCancel(ctx)
+	go func() {
+		defer close(f.done)
+		defer f.cancel()
+		f.value, f.err = fn(ctx)
+	}()
+	return f
+}
+
+// Done returns a channel closed once the call is done.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the call is done and returns its result, filling the ResponseMeta carried by the context of the
+// call if any. ctx only bounds the waiting: if it is done first, its error is returned and the call goes on (see Cancel).
+func (f *Future[T]) Wait(ctx context.Context) (value T, err error) {
+	select {
+	case <-f.done:
+		if f.metaDest != nil {
+			*f.metaDest = f.meta
+		}
+		return f.value, f.err
+	case <-ctx.Done():
+		return value, ctx.Err()
+	}
+}
+
+// Cancel cancels the call if it is not done yet. Its result will be the cancellation error.
+func (f *Future[T]) Cancel() {
+	f.cancel()
+}
+
+// SearchAsync starts a search query thru client and returns its Future right away. The values carried by ctx (priority,
+// tags, etc...) apply to the call and canceling ctx cancels it. A ResponseMeta carried by ctx is filled by Future.Wait.
+func SearchAsync(ctx context.Context, client Client, query SearchQuery) *Future[SearchAnswer] {
+	return newFuture(ctx, func(ctx context.Context) (SearchAnswer, error) {
+		return client.Search(ctx, query)
+	})
+}
+
+// ExtractAsync is the extract counterpart of SearchAsync.
+func ExtractAsync(ctx context.Context, client Client, request ExtractRequest) *Future[ExtractAnswer] {
+	return newFuture(ctx, func(ctx context.Context) (ExtractAnswer, error) {
+		return client.Extract(ctx, request)
+	})
+}
+
+// WaitAll waits for every future and returns their values in the same order. The errors of the failed calls
+// are joined, their values are left to their zero value. If ctx is done first, its error is returned.
+func WaitAll[T any](ctx context.Context, futures ...*Future[T]) (values []T, err error) {
+	values = make([]T, len(futures))
+	var errs []error
+	for index, future := range futures {
+		var callErr error
+		if values[index], callErr = future.Wait(ctx); callErr != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("call #%d: %w", index, callErr))
+		}
+	}
+	return values, errors.Join(errs...)
+}
+
+// WaitFirstN waits for the first n futures to be done (successfully or not) and returns them in completion order.
+// The other ones go on, they can be canceled if not needed anymore. If ctx is done first, its error is returned
+// alongside the futures done so far.
+func WaitFirstN[T any](ctx context.Context, n int, futures ...*Future[T]) (done []*Future[T], err error) {
+	if n < 0 || n > len(futures) {
+		return nil, fmt.Errorf("n must be within [0, %d]", len(futures))
+	}
+	completed := make(chan *Future[T], len(futures))
+	stop := make(chan struct{})
+	defer close(stop)
+	for _, future := range futures {
+		go func() {
+			select {
+			case <-future.done:
+				completed <- future
+			case <-stop:
+			}
+		}()
+	}
+	done = make([]*Future[T], 0, n)
+	for len(done) < n {
+		select {
+		case future := <-completed:
+			done = append(done, future)
+		case <-ctx.Done():
+			return done, ctx.Err()
+		}
+	}
+	return
+}
@@ -0,0 +1,96 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// blockingQueriesAPI returns a fake API blocking the searches of the "slow" query until their request is canceled.
+func blockingQueriesAPI() *fakeAPI {
+	return &fakeAPI{handler: func(req *http.Request, payload map[string]any) *http.Response {
+		switch payload["query"] {
+		case "slow":
+			<-req.Context().Done()
+			return jsonResponse(http.StatusServiceUnavailable, `{"detail":{"error":"unavailable"}}`)
+		case "invalid":
+			return jsonResponse(http.StatusBadRequest, `{"detail":{"error":"bad request"}}`)
+		}
+		return defaultFakeHandler(req, payload)
+	}}
+}
+
+func TestWaitAll(t *testing.T) {
+	root := newTestClient(t, blockingQueriesAPI(), ClientConfig{})
+	ctx := context.Background()
+	answers, err := WaitAll(ctx, SearchAsync(ctx, root, SearchQuery{Query: "a"}), SearchAsync(ctx, root, SearchQuery{Query: "b"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(answers) != 2 || len(answers[0].Results) != 1 || len(answers[1].Results) != 1 {
+		t.Errorf("unexpected answers: %+v", answers)
+	}
+	answers, err = WaitAll(ctx, SearchAsync(ctx, root, SearchQuery{Query: "a"}), SearchAsync(ctx, root, SearchQuery{Query: "invalid"}))
+	var apiErr APIError
+	if !errors.As(err, &apiErr) || !strings.Contains(err.Error(), "call #1") {
+		t.Fatalf("expected the failed call error, got %v", err)
+	}
+	if len(answers[0].Results) != 1 || len(answers[1].Results) != 0 {
+		t.Errorf("expected the successful value to be kept, got %+v", answers)
+	}
+	extract := ExtractAsync(ctx, root, ExtractRequest{URLs: []string{"https://example.com/"}})
+	if extracts, err := WaitAll(ctx, extract); err != nil || len(extracts[0].Results) != 1 {
+		t.Errorf("unexpected extract: %+v (%v)", extracts, err)
+	}
+	if stats := root.Stats(); stats.BasicSearches != 3 || stats.BasicExtracts != 1 {
+		t.Errorf("expected the asynchronous calls to be accounted, got %+v", stats)
+	}
+}
+
+func TestWaitFirstNAndCancel(t *testing.T) {
+	root := newTestClient(t, blockingQueriesAPI(), ClientConfig{})
+	ctx := context.Background()
+	slow := SearchAsync(ctx, root, SearchQuery{Query: "slow"})
+	fast := SearchAsync(ctx, root, SearchQuery{Query: "fast"})
+	done, err := WaitFirstN(ctx, 1, slow, fast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 1 || done[0] != fast {
+		t.Fatalf("expected the fast call first, got %v", done)
+	}
+	// waiting is bounded by its own context without canceling the call
+	waitCtx, cancelWait := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancelWait()
+	if _, err = slow.Wait(waitCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to time out, got %v", err)
+	}
+	select {
+	case <-slow.Done():
+		t.Fatal("expected the call to go on")
+	default:
+	}
+	slow.Cancel()
+	if _, err = slow.Wait(ctx); err == nil {
+		t.Error("expected the canceled call to fail")
+	}
+	if _, err = WaitFirstN(ctx, 3, slow, fast); err == nil {
+		t.Error("expected n to be checked")
+	}
+}
+
+func TestFutureParentContextCanceled(t *testing.T) {
+	root := newTestClient(t, blockingQueriesAPI(), ClientConfig{})
+	ctx, cancel := context.WithCancel(context.Background())
+	future := SearchAsync(ctx, root, SearchQuery{Query: "slow"})
+	cancel()
+	if _, err := future.Wait(context.Background()); err == nil {
+		t.Error("expected canceling the parent context to cancel the call")
+	}
+	if _, err := WaitAll(ctx, future); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected WaitAll to return the context error, got %v", err)
+	}
+}
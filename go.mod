@@ -4,5 +4,9 @@ go 1.23.4
 
 require (
 	github.com/hashicorp/go-cleanhttp v0.5.2
+	golang.org/x/net v0.42.0
 	golang.org/x/time v0.12.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require golang.org/x/text v0.27.0 // indirect
@@ -0,0 +1,116 @@
+package tavily
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeAPI is an http.RoundTripper answering the requests of the tests without network access.
+type fakeAPI struct {
+	access   sync.Mutex
+	handler  func(req *http.Request, payload map[string]any) *http.Response
+	requests []fakeRequest
+}
+
+type fakeRequest struct {
+	Endpoint string
+	Key      string
+	Payload  map[string]any
+}
+
+func (fa *fakeAPI) RoundTrip(req *http.Request) (*http.Response, error) {
+	var payload map[string]any
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > 0 {
+			if err = json.Unmarshal(data, &payload); err != nil {
+				return nil, err
+			}
+		}
+	}
+	request := fakeRequest{
+		Endpoint: strings.TrimPrefix(req.URL.Path, "/"),
+		Key:      strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "),
+		Payload:  payload,
+	}
+	fa.access.Lock()
+	fa.requests = append(fa.requests, request)
+	handler := fa.handler
+	fa.access.Unlock()
+	if handler == nil {
+		handler = defaultFakeHandler
+	}
+	resp := handler(req, payload)
+	resp.Request = req
+	return resp, nil
+}
+
+func (fa *fakeAPI) calls() []fakeRequest {
+	fa.access.Lock()
+	defer fa.access.Unlock()
+	return append([]fakeRequest(nil), fa.requests...)
+}
+
+// defaultFakeHandler answers a search with one result per query and an extract with one result per URL.
+func defaultFakeHandler(req *http.Request, payload map[string]any) *http.Response {
+	switch strings.TrimPrefix(req.URL.Path, "/") {
+	case EndpointSearch:
+		return jsonResponse(http.StatusOK, `{"query":"q","answer":null,"images":[],"results":[{"title":"t","url":"https://example.com/","content":"c","score":0.5,"raw_content":null}],"response_time":0.1}`)
+	case EndpointExtract:
+		var results []string
+		for _, u := range payload["urls"].([]any) {
+			results = append(results, `{"url":"`+u.(string)+`","raw_content":"content of `+u.(string)+`","images":[]}`)
+		}
+		return jsonResponse(http.StatusOK, `{"results":[`+strings.Join(results, ",")+`],"failed_results":[],"response_time":0.1}`)
+	}
+	return jsonResponse(http.StatusNotFound, `{"detail":{"error":"not found"}}`)
+}
+
+func jsonResponse(statusCode int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Status:     http.StatusText(statusCode),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+// testKey returns an API key fast enough to never make the tests wait.
+func testKey(key string) APIKey {
+	return APIKey{
+		Key:          key,
+		ReqPerMinute: 600000,
+	}
+}
+
+// newTestClient returns the root session of a client using api, with a single test key if conf does not set any.
+func newTestClient(t *testing.T, api *fakeAPI, conf ClientConfig) *Session {
+	t.Helper()
+	if len(conf.APIKeys) == 0 {
+		conf.APIKeys = []APIKey{testKey("tvly-dev-testkey0001")}
+	}
+	conf.HTTPClient = &http.Client{Transport: api}
+	client, err := NewClientWithConfig(conf)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func testSearch(t *testing.T, client Client) SearchAnswer {
+	t.Helper()
+	answer, err := client.Search(context.Background(), SearchQuery{Query: "q"})
+	if err != nil {
+		t.Fatalf("search failed: %v", err)
+	}
+	return answer
+}
@@ -0,0 +1,57 @@
+// Package fielddoc reads the comments of struct fields from Go source files. It is used to generate the descriptions
+// of the JSON schemas of the request types from their fields documentation.
+package fielddoc
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+// Comments returns the comments of the fields of the struct typeName declared in file, by JSON name. The trailing
+// comment of a field takes precedence over its doc comment.
+func Comments(file, typeName string) (comments map[string]string, err error) {
+	parsed, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+	ast.Inspect(parsed, func(node ast.Node) bool {
+		spec, ok := node.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != typeName {
+			return comments == nil
+		}
+		structType, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return false
+		}
+		comments = make(map[string]string, len(structType.Fields.List))
+		for _, field := range structType.Fields.List {
+			var tag reflect.StructTag
+			if field.Tag != nil {
+				tag = reflect.StructTag(strings.Trim(field.Tag.Value, "`"))
+			}
+			comment := field.Doc.Text()
+			if field.Comment != nil {
+				comment = field.Comment.Text()
+			}
+			for _, fieldName := range field.Names {
+				name, _, _ := strings.Cut(tag.Get("json"), ",")
+				switch {
+				case !fieldName.IsExported() || name == "-":
+					continue
+				case name == "":
+					name = fieldName.Name
+				}
+				comments[name] = strings.ReplaceAll(strings.TrimSpace(comment), "\n", " ")
+			}
+		}
+		return false
+	})
+	if comments == nil {
+		return nil, fmt.Errorf("struct %s not found in %s", typeName, file)
+	}
+	return
+}
@@ -0,0 +1,43 @@
+package fielddoc
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestComments(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "types.go")
+	source := `package types
+
+type Request struct {
+	// The doc comment,
+	// on two lines.
+	Documented string ` + "`json:\"documented\"`" + `
+	// Overridden by the trailing comment.
+	Both    int    ` + "`json:\"both,omitempty\"`" + ` // The trailing comment.
+	NoTag   bool   // Named after the field.
+	Ignored string ` + "`json:\"-\"`" + ` // Not marshaled.
+	private string
+}
+`
+	if err := os.WriteFile(file, []byte(source), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	comments, err := Comments(file, "Request")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"documented": "The doc comment, on two lines.",
+		"both":       "The trailing comment.",
+		"NoTag":      "Named after the field.",
+	}
+	if !maps.Equal(comments, expected) {
+		t.Errorf("unexpected comments: %v", comments)
+	}
+	if _, err = Comments(file, "Unknown"); err == nil {
+		t.Error("expected an error for an unknown type")
+	}
+}
@@ -0,0 +1,220 @@
+package tavily
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// JSONSchemaDialect is the JSON Schema draft the generated schemas conform to.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is a JSON Schema document, ready to be marshaled (eg as the parameters of an LLM tool definition or to generate a form).
+type JSONSchema map[string]any
+
+// jsonSchemaEnums holds the allowed values of the typed constants, by type.
+var jsonSchemaEnums = map[reflect.Type][]string{
+	reflect.TypeFor[SearchQueryTopic]():         {string(SearchQueryTopicGeneral), string(SearchQueryTopicNews)},
+	reflect.TypeFor[SearchQueryDepth]():         {string(SearchQueryDepthBasic), string(SearchQueryDepthAdvanced)},
+	reflect.TypeFor[SearchQueryTimeRange]():     {string(SearchQueryTimeRangeDay), string(SearchQueryTimeRangeWeek), string(SearchQueryTimeRangeMonth), string(SearchQueryTimeRangeYear)},
+	reflect.TypeFor[SearchQueryIncludeAnswer](): {string(SearchQueryIncludeAnswerBasic), string(SearchQueryIncludeAnswerAdvanced)},
+	reflect.TypeFor[ExtractRequestDepth]():      {string(ExtractRequestDepthBasic), string(ExtractRequestDepthAdvanced)},
+}
+
+//go:generate go run jsonschema_gen.go
+
+// jsonSchemaFields holds the constraints of the fields of a request type, by JSON name. Their descriptions are generated
+// from their comments (see jsonSchemaDescriptions).
+type jsonSchemaFields map[string]JSONSchema
+
+var searchQueryJSONSchemaFields = jsonSchemaFields{
+	"query": {
+		"minLength": 1,
+	},
+	"max_results": {
+		"minimum": 0,
+		"maximum": SearchMaxPossibleResults,
+	},
+	"days": {
+		"minimum": 0,
+	},
+	"include_domains": {
+		"maxItems":    SearchMaxIncludeDomains,
+		"uniqueItems": true,
+	},
+	"exclude_domains": {
+		"maxItems":    SearchMaxExcludeDomains,
+		"uniqueItems": true,
+	},
+}
+
+var extractRequestJSONSchemaFields = jsonSchemaFields{
+	"urls": {
+		"minItems": 1,
+		"maxItems": ExtractMaxURLs,
+		"items": JSONSchema{
+			"format": "uri",
+		},
+	},
+}
+
+// SearchQueryJSONSchema returns the JSON Schema of SearchQuery, including the cross fields constraints checked by SearchQuery.Validate.
+func SearchQueryJSONSchema() JSONSchema {
+	schema := generateJSONSchema(reflect.TypeFor[SearchQuery](), searchQueryJSONSchemaFields)
+	schema["$schema"] = JSONSchemaDialect
+	schema["title"] = "SearchQuery"
+	schema["description"] = "The parameters of a Tavily search query, see https://docs.tavily.com/api-reference/endpoint/search"
+	schema["required"] = []string{"query"}
+	schema["allOf"] = []JSONSchema{
+		// time_range is only available with the general topic, days with the news topic
+		{
+			"if": JSONSchema{
+				"properties": JSONSchema{"topic": JSONSchema{"const": SearchQueryTopicNews}},
+				"required":   []string{"topic"},
+			},
+			"then": JSONSchema{
+				"not": JSONSchema{"required": []string{"time_range"}},
+			},
+			"else": JSONSchema{
+				"properties": JSONSchema{"days": JSONSchema{"const": 0}},
+			},
+		},
+		// include_image_descriptions requires include_images
+		{
+			"if": JSONSchema{
+				"properties": JSONSchema{"include_image_descriptions": JSONSchema{"const": true}},
+				"required":   []string{"include_image_descriptions"},
+			},
+			"then": JSONSchema{
+				"properties": JSONSchema{"include_images": JSONSchema{"const": true}},
+				"required":   []string{"include_images"},
+			},
+		},
+	}
+	return schema
+}
+
+// ExtractRequestJSONSchema returns the JSON Schema of ExtractRequest.
+func ExtractRequestJSONSchema() JSONSchema {
+	schema := generateJSONSchema(reflect.TypeFor[ExtractRequest](), extractRequestJSONSchemaFields)
+	schema["$schema"] = JSONSchemaDialect
+	schema["title"] = "ExtractRequest"
+	schema["description"] = "The parameters of a Tavily extract request, see https://docs.tavily.com/api-reference/endpoint/extract"
+	schema["required"] = []string{"urls"}
+	return schema
+}
+
+// Only returns a copy of an object schema restricted to the properties names (eg to only expose some of the request
+// fields to an LLM, the other ones being set by the caller). The cross fields constraints are kept if they only refer
+// to kept properties.
+func (js JSONSchema) Only(names ...string) (schema JSONSchema) {
+	schema = maps.Clone(js)
+	if properties, ok := js["properties"].(JSONSchema); ok {
+		kept := make(JSONSchema, len(names))
+		for _, name := range names {
+			if property, found := properties[name]; found {
+				kept[name] = property
+			}
+		}
+		schema["properties"] = kept
+	}
+	removed := func(name string) bool { return !slices.Contains(names, name) }
+	if required, ok := js["required"].([]string); ok {
+		schema["required"] = slices.DeleteFunc(slices.Clone(required), removed)
+	}
+	if constraints, ok := js["allOf"].([]JSONSchema); ok {
+		constraints = slices.DeleteFunc(slices.Clone(constraints), func(constraint JSONSchema) bool {
+			return slices.ContainsFunc(referencedProperties(constraint), removed)
+		})
+		if len(constraints) > 0 {
+			schema["allOf"] = constraints
+		} else {
+			delete(schema, "allOf")
+		}
+	}
+	return
+}
+
+// referencedProperties returns the names of the properties a cross fields constraint refers to.
+func referencedProperties(constraint JSONSchema) (names []string) {
+	for keyword, value := range constraint {
+		switch value := value.(type) {
+		case JSONSchema:
+			if keyword == "properties" {
+				names = slices.AppendSeq(names, maps.Keys(value))
+			} else {
+				names = append(names, referencedProperties(value)...)
+			}
+		case []string:
+			if keyword == "required" {
+				names = append(names, value...)
+			}
+		}
+	}
+	return
+}
+
+// generateJSONSchema returns the schema of t based on the JSON names of its fields (if a struct) and their comments, the
+// allowed values of the typed constants and the constraints of fields.
+func generateJSONSchema(t reflect.Type, fields jsonSchemaFields) (schema JSONSchema) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	schema = make(JSONSchema)
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := make(JSONSchema, t.NumField())
+		for index := range t.NumField() {
+			field := t.Field(index)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			property := generateJSONSchema(field.Type, nil)
+			if description := jsonSchemaDescriptions[t.Name()][name]; description != "" {
+				property["description"] = description
+			}
+			mergeJSONSchema(property, fields[name])
+			properties[name] = property
+		}
+		schema["type"] = "object"
+		schema["properties"] = properties
+		schema["additionalProperties"] = false
+	case reflect.Slice, reflect.Array:
+		schema["type"] = "array"
+		schema["items"] = generateJSONSchema(t.Elem(), nil)
+	case reflect.Map:
+		schema["type"] = "object"
+		schema["additionalProperties"] = generateJSONSchema(t.Elem(), nil)
+	case reflect.String:
+		schema["type"] = "string"
+		if enum, found := jsonSchemaEnums[t]; found {
+			schema["enum"] = enum
+		}
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		schema["type"] = "integer"
+	case reflect.Float32, reflect.Float64:
+		schema["type"] = "number"
+	}
+	return
+}
+
+// mergeJSONSchema adds the keywords of src to dst, merging the nested schemas.
+func mergeJSONSchema(dst, src JSONSchema) {
+	for keyword, value := range src {
+		if srcNested, ok := value.(JSONSchema); ok {
+			if dstNested, ok := dst[keyword].(JSONSchema); ok {
+				mergeJSONSchema(dstNested, srcNested)
+				continue
+			}
+		}
+		dst[keyword] = value
+	}
+}
@@ -0,0 +1,26 @@
+// Code generated by "go run jsonschema_gen.go"; DO NOT EDIT.
+
+package tavily
+
+// jsonSchemaDescriptions holds the comments of the fields of the request types, by type and JSON name.
+var jsonSchemaDescriptions = map[string]map[string]string{
+	"SearchQuery": {
+		"days":                       "The number of days back from the current date to include in the search results. Only available with the \"news\" topic. Default is 3.",
+		"exclude_domains":            "A list of domains to specifically exclude from the search results. Default is empty, which doesn't exclude any domains.",
+		"include_answer":             "Include a short (basic) or detailed (advanced) answer to original query. Default is no answer.",
+		"include_domains":            "A list of domains to specifically include in the search results. Default is empty, which includes all domains.",
+		"include_image_descriptions": "When include_images is true, this option adds descriptive text for each image. Default is false.",
+		"include_images":             "Include a list of query-related images in the response. Default is false.",
+		"include_raw_content":        "Include the cleaned and parsed HTML content of each search result. Default is false.",
+		"max_results":                "The maximum number of search results to return. Default is 5.",
+		"query":                      "The search query you want to execute with Tavily.",
+		"search_depth":               "The depth of the search. Default is \"basic\".",
+		"time_range":                 "The time range back from the current date to filter results. Only available with the \"general\" topic.",
+		"topic":                      "The category of the search. This will determine which of our agents will be used for the search. Default is \"general\".",
+	},
+	"ExtractRequest": {
+		"extract_depth":  "The depth of the extraction process. Advanced extraction retrieves more data (tables, embedded content) but costs more credits. Default is \"basic\".",
+		"include_images": "Include a list of images extracted from the URLs in the response. Default is false.",
+		"urls":           "The URLs of the web pages to extract content from.",
+	},
+}
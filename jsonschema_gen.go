@@ -0,0 +1,51 @@
+//go:build ignore
+
+// jsonschema_gen generates jsonschema_descriptions.go from the comments of the request types fields.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/hekmon/tavily/v2/internal/fielddoc"
+)
+
+// requestTypes lists the request types having a JSON schema, with the file declaring them.
+var requestTypes = []struct {
+	file, typeName string
+}{
+	{"search.go", "SearchQuery"},
+	{"extract.go", "ExtractRequest"},
+}
+
+func main() {
+	var source bytes.Buffer
+	source.WriteString("// Code generated by \"go run jsonschema_gen.go\"; DO NOT EDIT.\n\n")
+	source.WriteString("package tavily\n\n")
+	source.WriteString("// jsonSchemaDescriptions holds the comments of the fields of the request types, by type and JSON name.\n")
+	source.WriteString("var jsonSchemaDescriptions = map[string]map[string]string{\n")
+	for _, requestType := range requestTypes {
+		comments, err := fielddoc.Comments(requestType.file, requestType.typeName)
+		if err != nil {
+			log.Fatalf("failed to read the %s fields comments: %s", requestType.typeName, err)
+		}
+		fmt.Fprintf(&source, "%q: {\n", requestType.typeName)
+		for _, name := range slices.Sorted(maps.Keys(comments)) {
+			fmt.Fprintf(&source, "%q: %q,\n", name, comments[name])
+		}
+		source.WriteString("},\n")
+	}
+	source.WriteString("}\n")
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		log.Fatalf("failed to format the generated source: %s", err)
+	}
+	if err = os.WriteFile("jsonschema_descriptions.go", formatted, 0o644); err != nil {
+		log.Fatalf("failed to write the generated source: %s", err)
+	}
+}
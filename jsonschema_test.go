@@ -0,0 +1,84 @@
+package tavily
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/hekmon/tavily/v2/internal/fielddoc"
+)
+
+func TestJSONSchemaDescriptions(t *testing.T) {
+	for _, schema := range []struct {
+		file   string
+		schema JSONSchema
+	}{
+		{"search.go", SearchQueryJSONSchema()},
+		{"extract.go", ExtractRequestJSONSchema()},
+	} {
+		typeName := schema.schema["title"].(string)
+		comments, err := fielddoc.Comments(schema.file, typeName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !maps.Equal(comments, jsonSchemaDescriptions[typeName]) {
+			t.Fatalf("%s: the descriptions are not up to date with the fields comments, run go generate", typeName)
+		}
+		for name, property := range schema.schema["properties"].(JSONSchema) {
+			if description := property.(JSONSchema)["description"]; description != comments[name] {
+				t.Errorf("%s.%s: unexpected description %q", typeName, name, description)
+			}
+		}
+	}
+}
+
+func TestSearchQueryJSONSchema(t *testing.T) {
+	schema := SearchQueryJSONSchema()
+	if schema["$schema"] != JSONSchemaDialect || schema["additionalProperties"] != false || !slices.Equal(schema["required"].([]string), []string{"query"}) {
+		t.Errorf("unexpected schema root: %v", schema)
+	}
+	properties := schema["properties"].(JSONSchema)
+	if len(properties) != reflect.TypeFor[SearchQuery]().NumField() {
+		t.Errorf("expected a property per field, got %d", len(properties))
+	}
+	topic := properties["topic"].(JSONSchema)
+	if topic["type"] != "string" || !slices.Equal(topic["enum"].([]string), []string{"general", "news"}) {
+		t.Errorf("unexpected topic property: %v", topic)
+	}
+	maxResults := properties["max_results"].(JSONSchema)
+	if maxResults["type"] != "integer" || maxResults["maximum"] != SearchMaxPossibleResults {
+		t.Errorf("unexpected max_results property: %v", maxResults)
+	}
+	domains := properties["include_domains"].(JSONSchema)
+	if domains["type"] != "array" || domains["items"].(JSONSchema)["type"] != "string" || domains["maxItems"] != SearchMaxIncludeDomains {
+		t.Errorf("unexpected include_domains property: %v", domains)
+	}
+	if _, err := json.Marshal(schema); err != nil {
+		t.Errorf("failed to marshal the schema: %v", err)
+	}
+}
+
+func TestJSONSchemaOnly(t *testing.T) {
+	full := ExtractRequestJSONSchema()
+	schema := full.Only("urls", "unknown")
+	properties := schema["properties"].(JSONSchema)
+	if len(properties) != 1 || properties["urls"].(JSONSchema)["items"].(JSONSchema)["format"] != "uri" {
+		t.Errorf("unexpected properties: %v", properties)
+	}
+	if !slices.Equal(schema["required"].([]string), []string{"urls"}) || schema["title"] != "ExtractRequest" {
+		t.Errorf("unexpected schema: %v", schema)
+	}
+	if schema = SearchQueryJSONSchema().Only("topic"); len(schema["required"].([]string)) != 0 || schema["allOf"] != nil {
+		t.Errorf("expected the required fields and the constraints on removed properties to be filtered: %v", schema)
+	}
+	// only the constraints referring to kept properties remain
+	schema = SearchQueryJSONSchema().Only("query", "topic", "time_range", "days")
+	if constraints := schema["allOf"].([]JSONSchema); len(constraints) != 1 || !slices.Contains(referencedProperties(constraints[0]), "days") {
+		t.Errorf("expected the topic constraint to be kept alone: %v", constraints)
+	}
+	if len(full["properties"].(JSONSchema)) != 3 {
+		t.Error("expected the original schema to be left untouched")
+	}
+}
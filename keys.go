@@ -0,0 +1,379 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// APIKey represents a Tavily API key of the client pool.
+type APIKey struct {
+	Key string
+	// Optional requests per minute allowed for this key. Default is detected from the key prefix (ReqPerMinuteDev or ReqPerMinuteProd).
+	// Required for keys with another format (enterprise or custom keys).
+	ReqPerMinute int
+	// Optional maximum number of requests that can be sent at once. Default is ReqPerMinute.
+	Burst int
+	// Optional additional limits for specific endpoints (see the Endpoint* constants), applied on top of the key limit.
+	// Endpoints not yet supported by this client (eg "crawl") can be set in advance.
+	EndpointsLimits map[string]RateLimit
+	// Optional custom rate limiter for this key, used instead of the one built from ReqPerMinute and Burst (which remain informative).
+	// Endpoints limits still apply on top of it.
+	RateLimiter RateLimiter
+}
+
+// defaultReqPerMinute returns the rate limit of a key based on its prefix, 0 if the key format is unknown.
+func defaultReqPerMinute(key string) int {
+	switch {
+	case strings.HasPrefix(key, devKeyPrefix):
+		return ReqPerMinuteDev
+	case strings.HasPrefix(key, prodKeyPrefix):
+		return ReqPerMinuteProd
+	default:
+		return 0
+	}
+}
+
+// KeyStrategy defines how an API key is selected from the pool for each request.
+type KeyStrategy string
+
+const (
+	// KeyStrategyRoundRobin uses each available key in turn.
+	KeyStrategyRoundRobin KeyStrategy = "round_robin"
+	// KeyStrategyLeastLoaded uses the available key with the fewest in flight requests, then the one which has consumed the fewest credits.
+	KeyStrategyLeastLoaded KeyStrategy = "least_loaded"
+	// KeyStrategyFailover always uses the first available key in the order of the configuration, the next ones are used only when the previous ones get disabled.
+	KeyStrategyFailover KeyStrategy = "failover"
+)
+
+// KeyStats represents the usage statistics of an API key of the pool.
+type KeyStats struct {
+	Key            string    // Redacted version of the key, safe to log.
+	ReqPerMinute   int       // The rate limit applied to this key.
+	EffectiveRate  float64   // The current effective requests per minute of this key, lower than ReqPerMinute when adaptive rate limiting is throttling it.
+	Requests       int       // Number of requests sent with this key.
+	InFlight       int       // Number of requests currently using this key.
+	Credits        float64   // API credits consumed with this key by the successful requests.
+	Disabled       bool      // Whether the key is out of the rotation after an authentication or quota error, until its cooldown expires.
+	DisabledReason string    // The error that disabled the key, if any.
+	DisabledUntil  time.Time // When the disabled key will be tried again, zero if never (see ClientConfig.DisabledKeyCooldown).
+}
+
+type keyPool struct {
+	keys     []*poolKey
+	strategy KeyStrategy
+	cooldown time.Duration // negative: disabled keys are never tried again
+	next     atomic.Uint64
+	access   sync.Mutex // serializes the keys disabling
+}
+
+// keyPoolConfig holds the client configuration applied to each key of the pool.
+type keyPoolConfig struct {
+	strategy      KeyStrategy
+	sharedStore   SharedRateLimiterStore
+	adaptive      *AdaptiveRateLimit
+	priorityAging time.Duration
+	cooldown      time.Duration
+}
+
+func newKeyPool(keys []APIKey, conf keyPoolConfig) (kp *keyPool, err error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one API key is required")
+	}
+	switch conf.strategy {
+	case "":
+		conf.strategy = KeyStrategyRoundRobin
+	case KeyStrategyRoundRobin, KeyStrategyLeastLoaded, KeyStrategyFailover:
+	default:
+		return nil, fmt.Errorf("invalid key strategy %q", conf.strategy)
+	}
+	if conf.adaptive != nil {
+		if err = conf.adaptive.validate(); err != nil {
+			return nil, fmt.Errorf("invalid adaptive rate limit configuration: %w", err)
+		}
+	}
+	if conf.cooldown == 0 {
+		conf.cooldown = DefaultDisabledKeyCooldown
+	}
+	kp = &keyPool{
+		keys:     make([]*poolKey, len(keys)),
+		strategy: conf.strategy,
+		cooldown: conf.cooldown,
+	}
+	seen := make(map[string]struct{}, len(keys))
+	for index, key := range keys {
+		if _, found := seen[key.Key]; found {
+			return nil, fmt.Errorf("API key #%d (%s) is duplicated", index, redactKey(key.Key))
+		}
+		seen[key.Key] = struct{}{}
+		if kp.keys[index], err = newPoolKey(key, conf); err != nil {
+			return nil, fmt.Errorf("API key #%d (%s): %w", index, redactKey(key.Key), err)
+		}
+	}
+	return
+}
+
+// acquire selects a key according to the pool strategy and marks it as in flight. Caller must call release on the returned key.
+func (kp *keyPool) acquire() (key *poolKey, err error) {
+	now := time.Now()
+	switch kp.strategy {
+	case KeyStrategyLeastLoaded:
+		for _, candidate := range kp.keys {
+			if !candidate.available(now) {
+				continue
+			}
+			if key == nil || candidate.loadedLessThan(key) {
+				key = candidate
+			}
+		}
+	case KeyStrategyFailover:
+		for _, candidate := range kp.keys {
+			if candidate.available(now) {
+				key = candidate
+				break
+			}
+		}
+	default:
+		start := kp.next.Add(1) - 1
+		for i := range uint64(len(kp.keys)) {
+			if candidate := kp.keys[(start+i)%uint64(len(kp.keys))]; candidate.available(now) {
+				key = candidate
+				break
+			}
+		}
+	}
+	if key == nil {
+		return nil, ErrNoAvailableAPIKey
+	}
+	key.inFlight.Add(1)
+	key.requests.Add(1)
+	return
+}
+
+// disable removes key from the rotation until the cooldown expires, unless it is the last available key of the pool:
+// the error of the API is then returned to the caller instead. It returns whether the key has been disabled.
+func (kp *keyPool) disable(key *poolKey, reason error) bool {
+	kp.access.Lock()
+	defer kp.access.Unlock()
+	now := time.Now()
+	if !key.available(now) {
+		// already disabled by a concurrent request
+		return true
+	}
+	var others bool
+	for _, other := range kp.keys {
+		if other != key && other.available(now) {
+			others = true
+			break
+		}
+	}
+	if !others {
+		return false
+	}
+	until := time.Time{}
+	if kp.cooldown > 0 {
+		until = now.Add(kp.cooldown)
+	}
+	key.disable(reason, until)
+	return true
+}
+
+// reqPerMinute returns the total effective rate of the available keys.
+func (kp *keyPool) reqPerMinute() (total float64) {
+	now := time.Now()
+	for _, key := range kp.keys {
+		if key.available(now) {
+			total += key.effectiveReqPerMinute()
+		}
+	}
+	return
+}
+
+func (kp *keyPool) stats() (stats []KeyStats) {
+	stats = make([]KeyStats, len(kp.keys))
+	for index, key := range kp.keys {
+		stats[index] = key.stats()
+	}
+	return
+}
+
+type poolKey struct {
+	value        string
+	reqPerMinute int
+	// Controllers
+	throughput *scheduler // in front of the adaptive limiter (if enabled) and the key limiter
+	endpoints  map[string]RateLimiter
+	adaptive   *adaptiveLimiter
+	// Accounting
+	requests atomic.Int64
+	inFlight atomic.Int64
+	credits  atomic.Uint64 // float64 bits
+	disabled atomic.Int64  // Unix nanoseconds until which the key is disabled, 0 if enabled, math.MaxInt64 for ever
+	reason   error
+	access   sync.Mutex // protects reason
+}
+
+func newPoolKey(key APIKey, conf keyPoolConfig) (pk *poolKey, err error) {
+	if key.Key == "" {
+		return nil, errors.New("empty key")
+	}
+	limit := RateLimit{
+		ReqPerMinute: key.ReqPerMinute,
+		Burst:        key.Burst,
+	}
+	if limit.ReqPerMinute == 0 {
+		if limit.ReqPerMinute = defaultReqPerMinute(key.Key); limit.ReqPerMinute == 0 {
+			return nil, errors.New("key does not seem to be a valid Tavily API key, its requests per minute limit must be set explicitly")
+		}
+	}
+	if err = limit.validate(); err != nil {
+		return nil, fmt.Errorf("invalid rate limit: %w", err)
+	}
+	pk = &poolKey{
+		value:        key.Key,
+		reqPerMinute: limit.ReqPerMinute,
+		endpoints:    make(map[string]RateLimiter, len(key.EndpointsLimits)),
+	}
+	sharedName := SharedRateLimiterName(key.Key)
+	throughput := key.RateLimiter
+	if throughput == nil {
+		if throughput, err = newRateLimiter(limit, conf.sharedStore, sharedName); err != nil {
+			return nil, fmt.Errorf("failed to create rate limiter: %w", err)
+		}
+	}
+	if conf.adaptive != nil {
+		pk.adaptive = newAdaptiveLimiter(*conf.adaptive, limit)
+		throughput = chainedLimiter{pk.adaptive, throughput}
+	}
+	pk.throughput = newScheduler(throughput, conf.priorityAging)
+	for endpoint, endpointLimit := range key.EndpointsLimits {
+		if err = endpointLimit.validate(); err != nil {
+			return nil, fmt.Errorf("invalid rate limit for endpoint %q: %w", endpoint, err)
+		}
+		if pk.endpoints[endpoint], err = newRateLimiter(endpointLimit, conf.sharedStore, sharedName+":"+endpoint); err != nil {
+			return nil, fmt.Errorf("failed to create rate limiter for endpoint %q: %w", endpoint, err)
+		}
+	}
+	return
+}
+
+// wait blocks until the adaptive limit (if enabled), the key limit and the endpoint limit (if any) allow a request.
+// Requests waiting for the key limits are served according to the priority carried by ctx. The endpoint limit is waited
+// for last so a call cancelled while queued for the key (the longest wait) does not consume an endpoint token.
+func (pk *poolKey) wait(ctx context.Context, endpoint string) error {
+	if err := pk.throughput.Wait(ctx); err != nil {
+		return err
+	}
+	if limiter, found := pk.endpoints[endpoint]; found {
+		return limiter.Wait(ctx)
+	}
+	return nil
+}
+
+// observe feeds the adaptive limiter (if enabled) with an API response.
+func (pk *poolKey) observe(resp *http.Response) {
+	if pk.adaptive != nil {
+		pk.adaptive.observe(resp.StatusCode, resp.Header)
+	}
+}
+
+func (pk *poolKey) release() {
+	pk.inFlight.Add(-1)
+}
+
+func (pk *poolKey) addCredits(credits float64) {
+	for {
+		old := pk.credits.Load()
+		if pk.credits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+credits)) {
+			return
+		}
+	}
+}
+
+// available returns whether the key is in the pool rotation: enabled or its cooldown expired (it is then tried again).
+func (pk *poolKey) available(now time.Time) bool {
+	until := pk.disabled.Load()
+	return until == 0 || now.UnixNano() >= until
+}
+
+// disable removes the key from the pool rotation until a given time, for ever if zero.
+func (pk *poolKey) disable(reason error, until time.Time) {
+	pk.access.Lock()
+	defer pk.access.Unlock()
+	pk.reason = reason
+	if until.IsZero() {
+		pk.disabled.Store(math.MaxInt64)
+	} else {
+		pk.disabled.Store(until.UnixNano())
+	}
+}
+
+// enable puts back the key in the pool rotation, eg once it has been accepted again by the API after its cooldown.
+func (pk *poolKey) enable() {
+	if pk.disabled.Load() == 0 {
+		return
+	}
+	pk.access.Lock()
+	defer pk.access.Unlock()
+	pk.reason = nil
+	pk.disabled.Store(0)
+}
+
+// effectiveReqPerMinute returns the current rate of the key, lowered by the adaptive rate limiting if enabled.
+func (pk *poolKey) effectiveReqPerMinute() float64 {
+	if pk.adaptive != nil {
+		return pk.adaptive.effectiveReqPerMinute()
+	}
+	return float64(pk.reqPerMinute)
+}
+
+func (pk *poolKey) loadedLessThan(other *poolKey) bool {
+	if a, b := pk.inFlight.Load(), other.inFlight.Load(); a != b {
+		return a < b
+	}
+	return math.Float64frombits(pk.credits.Load()) < math.Float64frombits(other.credits.Load())
+}
+
+func (pk *poolKey) stats() (s KeyStats) {
+	s.Key = redactKey(pk.value)
+	s.ReqPerMinute = pk.reqPerMinute
+	s.EffectiveRate = pk.effectiveReqPerMinute()
+	s.Requests = int(pk.requests.Load())
+	s.InFlight = int(pk.inFlight.Load())
+	s.Credits = math.Float64frombits(pk.credits.Load())
+	pk.access.Lock()
+	defer pk.access.Unlock()
+	if pk.available(time.Now()) {
+		// enabled, or back in the rotation once its cooldown expired
+		return
+	}
+	s.Disabled = true
+	if until := pk.disabled.Load(); until != math.MaxInt64 {
+		s.DisabledUntil = time.Unix(0, until)
+	}
+	if pk.reason != nil {
+		s.DisabledReason = pk.reason.Error()
+	}
+	return
+}
+
+// redactKey returns a version of an API key safe to be logged.
+func redactKey(key string) string {
+	var prefix string
+	for _, knownPrefix := range []string{devKeyPrefix, prodKeyPrefix} {
+		if strings.HasPrefix(key, knownPrefix) {
+			prefix = knownPrefix
+			break
+		}
+	}
+	if len(key)-len(prefix) <= 8 {
+		return prefix + "****"
+	}
+	return prefix + "****" + key[len(key)-4:]
+}
@@ -0,0 +1,174 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// rejectingKeysAPI returns a fake API answering 401 to the requests made with one of the rejected keys.
+func rejectingKeysAPI(rejected map[string]*atomic.Bool) *fakeAPI {
+	return &fakeAPI{handler: func(req *http.Request, payload map[string]any) *http.Response {
+		key := req.Header.Get("Authorization")[len("Bearer "):]
+		if reject, found := rejected[key]; found && reject.Load() {
+			return jsonResponse(http.StatusUnauthorized, `{"detail":{"error":"Unauthorized: missing or invalid API key."}}`)
+		}
+		return defaultFakeHandler(req, payload)
+	}}
+}
+
+func TestKeyStrategies(t *testing.T) {
+	keys := []APIKey{testKey("tvly-dev-testkey0001"), testKey("tvly-dev-testkey0002"), testKey("tvly-dev-testkey0003")}
+	for strategy, expected := range map[KeyStrategy][]string{
+		KeyStrategyRoundRobin: {"tvly-dev-testkey0001", "tvly-dev-testkey0002", "tvly-dev-testkey0003", "tvly-dev-testkey0001"},
+		KeyStrategyFailover:   {"tvly-dev-testkey0001", "tvly-dev-testkey0001", "tvly-dev-testkey0001", "tvly-dev-testkey0001"},
+	} {
+		api := &fakeAPI{}
+		client := newTestClient(t, api, ClientConfig{APIKeys: keys, KeyStrategy: strategy})
+		for range expected {
+			testSearch(t, client)
+		}
+		for index, call := range api.calls() {
+			if call.Key != expected[index] {
+				t.Errorf("%s: call #%d used key %s, expected %s", strategy, index, call.Key, expected[index])
+			}
+		}
+	}
+	if _, err := NewClientWithConfig(ClientConfig{APIKeys: keys, KeyStrategy: "random"}); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+	if _, err := NewClientWithConfig(ClientConfig{APIKeys: []APIKey{keys[0], keys[0]}}); err == nil {
+		t.Error("expected an error for duplicated keys")
+	}
+}
+
+func TestKeyDisabledAndRetried(t *testing.T) {
+	rejected := map[string]*atomic.Bool{"tvly-dev-testkey0001": {}}
+	rejected["tvly-dev-testkey0001"].Store(true)
+	api := rejectingKeysAPI(rejected)
+	client := newTestClient(t, api, ClientConfig{
+		APIKeys:             []APIKey{testKey("tvly-dev-testkey0001"), testKey("tvly-dev-testkey0002")},
+		KeyStrategy:         KeyStrategyFailover,
+		DisabledKeyCooldown: 50 * time.Millisecond,
+	})
+	testSearch(t, client)
+	stats := client.Keys()
+	if !stats[0].Disabled || stats[0].DisabledReason == "" || stats[0].DisabledUntil.IsZero() {
+		t.Fatalf("expected the first key to be disabled: %+v", stats[0])
+	}
+	if stats[1].Disabled || stats[1].Credits != 1 {
+		t.Fatalf("expected the second key to be used: %+v", stats[1])
+	}
+	// the key is tried again once its cooldown expires and put back in the rotation if accepted
+	rejected["tvly-dev-testkey0001"].Store(false)
+	time.Sleep(60 * time.Millisecond)
+	if stats = client.Keys(); stats[0].Disabled {
+		t.Fatalf("expected the first key not to be reported disabled once its cooldown expired: %+v", stats[0])
+	}
+	testSearch(t, client)
+	if stats = client.Keys(); stats[0].Disabled || stats[0].Credits != 1 {
+		t.Fatalf("expected the first key to be enabled again: %+v", stats[0])
+	}
+}
+
+func TestLastKeyNeverDisabled(t *testing.T) {
+	rejected := map[string]*atomic.Bool{"tvly-dev-testkey0001": {}}
+	rejected["tvly-dev-testkey0001"].Store(true)
+	client := newTestClient(t, rejectingKeysAPI(rejected), ClientConfig{})
+	for range 2 {
+		_, err := client.Search(context.Background(), SearchQuery{Query: "q"})
+		var apiErr APIError
+		if !errors.As(err, &apiErr) || apiErr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected the API error, got %v", err)
+		}
+		if errors.Is(err, ErrNoAvailableAPIKey) {
+			t.Fatal("the last key must not be disabled")
+		}
+	}
+	if client.Keys()[0].Disabled {
+		t.Fatal("the last key must not be disabled")
+	}
+	// the key is accepted again (eg quota reset)
+	rejected["tvly-dev-testkey0001"].Store(false)
+	testSearch(t, client)
+}
+
+func TestKeyDisabledForEver(t *testing.T) {
+	rejected := map[string]*atomic.Bool{"tvly-dev-testkey0001": {}}
+	rejected["tvly-dev-testkey0001"].Store(true)
+	client := newTestClient(t, rejectingKeysAPI(rejected), ClientConfig{
+		APIKeys:             []APIKey{testKey("tvly-dev-testkey0001"), testKey("tvly-dev-testkey0002")},
+		KeyStrategy:         KeyStrategyFailover,
+		DisabledKeyCooldown: -1,
+	})
+	testSearch(t, client)
+	if stats := client.Keys(); !stats[0].Disabled || !stats[0].DisabledUntil.IsZero() {
+		t.Fatalf("expected the first key to be disabled for ever: %+v", stats[0])
+	}
+}
+
+func TestRedactKey(t *testing.T) {
+	for key, expected := range map[string]string{
+		"tvly-dev-abcdefghijkl": "tvly-dev-****ijkl",
+		"tvly-prod-short":       "tvly-prod-****",
+		"custom-key-123456789":  "****6789",
+	} {
+		if got := redactKey(key); got != expected {
+			t.Errorf("%q: expected %q, got %q", key, expected, got)
+		}
+	}
+}
+
+// blockingLimiter is a RateLimiter blocking until its context is done.
+type blockingLimiter struct{}
+
+func (blockingLimiter) Wait(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestEndpointTokenNotLostOnCancel(t *testing.T) {
+	key := testKey("tvly-dev-testkey0001")
+	key.RateLimiter = blockingLimiter{}
+	key.EndpointsLimits = map[string]RateLimit{EndpointExtract: {ReqPerMinute: 1, Burst: 1}}
+	pool, err := newKeyPool([]APIKey{key}, keyPoolConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk := pool.keys[0]
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err = pk.wait(ctx, EndpointExtract); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to time out, got %v", err)
+	}
+	if tokens := pk.endpoints[EndpointExtract].(*rate.Limiter).Tokens(); tokens < 0.99 {
+		t.Fatalf("expected the endpoint token to be given back, %g tokens left", tokens)
+	}
+}
+
+func TestEndpointLimitApplied(t *testing.T) {
+	key := testKey("tvly-dev-testkey0001")
+	key.EndpointsLimits = map[string]RateLimit{EndpointExtract: {ReqPerMinute: 1, Burst: 1}}
+	pool, err := newKeyPool([]APIKey{key}, keyPoolConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk := pool.keys[0]
+	if err = pk.wait(context.Background(), EndpointExtract); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err = pk.wait(ctx, EndpointExtract); err == nil {
+		t.Fatal("expected the second extract to wait for the endpoint limit")
+	}
+	// other endpoints are not limited
+	if err = pk.wait(context.Background(), EndpointSearch); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,172 @@
+package tavily
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultCheckpointInterval is the default interval between two checkpoints of a persisted session stats.
+const DefaultCheckpointInterval = time.Minute
+
+// StatsStore persists the stats of named sessions across restarts.
+type StatsStore interface {
+	// Load returns the stats saved under name. found is false if nothing has been saved yet.
+	Load(ctx context.Context, name string) (stats Stats, found bool, err error)
+	// Save replaces the stats saved under name.
+	Save(ctx context.Context, name string, stats Stats) error
+}
+
+// FileStatsStore is a StatsStore saving each session stats as a JSON file within a directory.
+type FileStatsStore struct {
+	dir string
+}
+
+// NewFileStatsStore returns a store using dir, created if it does not exist.
+func NewFileStatsStore(dir string) (*FileStatsStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	return &FileStatsStore{
+		dir: dir,
+	}, nil
+}
+
+func (fss *FileStatsStore) path(name string) string {
+	return filepath.Join(fss.dir, url.PathEscape(name)+".json")
+}
+
+// Load implements StatsStore.
+func (fss *FileStatsStore) Load(ctx context.Context, name string) (stats Stats, found bool, err error) {
+	data, err := os.ReadFile(fss.path(name))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		return
+	}
+	if err = json.Unmarshal(data, &stats); err != nil {
+		err = fmt.Errorf("failed to unmarshal stats file: %w", err)
+		return
+	}
+	found = true
+	return
+}
+
+// Save implements StatsStore. The file is replaced atomically.
+func (fss *FileStatsStore) Save(ctx context.Context, name string, stats Stats) (err error) {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("failed to marshal stats: %w", err)
+	}
+	tmp, err := os.CreateTemp(fss.dir, ".tmp-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), fss.path(name)); err != nil {
+		return fmt.Errorf("failed to replace stats file: %w", err)
+	}
+	return
+}
+
+// sessionPersistence periodically checkpoints the stats of a session into a store.
+type sessionPersistence struct {
+	store    StatsStore
+	key      string
+	interval time.Duration
+	onError  func(error)
+	stop     chan struct{}
+}
+
+// persistenceKey returns the name under which the stats of the session are saved: its path within the sessions tree
+// (without the leading separator of an unnamed root session).
+func (s *Session) persistenceKey() string {
+	return strings.TrimPrefix(strings.Join(s.path(), "/"), "/")
+}
+
+// newPersistence validates the persistence configuration of the session.
+func (s *Session) newPersistence(conf SessionConfig) (*sessionPersistence, error) {
+	if s.name == "" {
+		return nil, errors.New("a persisted session must be named")
+	}
+	if conf.CheckpointInterval < 0 {
+		return nil, errors.New("checkpoint interval must be positive")
+	}
+	p := &sessionPersistence{
+		store:    conf.StatsStore,
+		key:      s.persistenceKey(),
+		interval: conf.CheckpointInterval,
+		onError:  conf.OnCheckpointError,
+		stop:     make(chan struct{}),
+	}
+	if p.interval == 0 {
+		p.interval = DefaultCheckpointInterval
+	}
+	return p, nil
+}
+
+// restore loads the saved stats of the session into its counters (and the ones of its parents not persisted) and starts the
+// periodic checkpoints.
+func (s *Session) restore() error {
+	p := s.persistence
+	saved, found, err := p.store.Load(context.Background(), p.key)
+	if err != nil {
+		return fmt.Errorf("failed to load saved stats: %w", err)
+	}
+	if found {
+		s.statsCounter.add(saved)
+		// keep the stats of the parents including the ones of their sub sessions: a persisted parent already restored
+		// them within its own saved stats (as well as its own parents)
+		for parent := s.parent; parent != nil && parent.persistence == nil; parent = parent.parent {
+			parent.statsCounter.add(saved)
+		}
+	}
+	go p.run(s)
+	return nil
+}
+
+// run checkpoints the stats of s periodically until the session is closed.
+func (p *sessionPersistence) run(s *Session) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+		if err := s.checkpoint(context.Background()); err != nil && p.onError != nil {
+			p.onError(err)
+		}
+	}
+}
+
+// checkpoint saves the current stats of the session.
+func (s *Session) checkpoint(ctx context.Context) error {
+	if s.persistence == nil {
+		return nil
+	}
+	if err := s.persistence.store.Save(ctx, s.persistence.key, s.Stats()); err != nil {
+		return fmt.Errorf("failed to save stats of session %q: %w", s.persistence.key, err)
+	}
+	return nil
+}
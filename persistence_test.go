@@ -0,0 +1,170 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryStatsStore is a StatsStore keeping the stats in memory.
+type memoryStatsStore struct {
+	access sync.Mutex
+	stats  map[string]Stats
+	saves  int
+}
+
+func (mss *memoryStatsStore) Load(_ context.Context, name string) (Stats, bool, error) {
+	mss.access.Lock()
+	defer mss.access.Unlock()
+	stats, found := mss.stats[name]
+	return stats, found, nil
+}
+
+func (mss *memoryStatsStore) Save(_ context.Context, name string, stats Stats) error {
+	mss.access.Lock()
+	defer mss.access.Unlock()
+	if mss.stats == nil {
+		mss.stats = make(map[string]Stats)
+	}
+	mss.stats[name] = stats
+	mss.saves++
+	return nil
+}
+
+func (mss *memoryStatsStore) get(name string) Stats {
+	stats, _, _ := mss.Load(context.Background(), name)
+	return stats
+}
+
+func TestFileStatsStore(t *testing.T) {
+	store, err := NewFileStatsStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, found, err := store.Load(ctx, "tenant/a"); err != nil || found {
+		t.Fatalf("expected nothing saved yet, got found=%v err=%v", found, err)
+	}
+	saved := Stats{BasicSearches: 4, AdvancedExtracts: 2}
+	if err = store.Save(ctx, "tenant/a", saved); err != nil {
+		t.Fatal(err)
+	}
+	loaded, found, err := store.Load(ctx, "tenant/a")
+	if err != nil || !found || loaded != saved {
+		t.Fatalf("unexpected load: %+v found=%v err=%v", loaded, found, err)
+	}
+}
+
+func TestSessionPersistence(t *testing.T) {
+	store := &memoryStatsStore{}
+	api := &fakeAPI{}
+	// first run
+	root := newTestClient(t, api, ClientConfig{})
+	tenant, err := root.NewSessionWithConfig(SessionConfig{Name: "tenant", StatsStore: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conversation, err := tenant.NewSessionWithConfig(SessionConfig{Name: "conv", StatsStore: store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSearch(t, tenant)
+	testSearch(t, conversation)
+	// closing the root cascades to the persisted sub sessions
+	if err = root.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.get("tenant").BasicSearches; got != 2 {
+		t.Fatalf("expected 2 searches saved for the tenant, got %d", got)
+	}
+	if got := store.get("tenant/conv").BasicSearches; got != 1 {
+		t.Fatalf("expected 1 search saved for the conversation, got %d", got)
+	}
+	// second run: the persisted tenant already includes the conversation stats
+	root = newTestClient(t, api, ClientConfig{})
+	if tenant, err = root.NewSessionWithConfig(SessionConfig{Name: "tenant", StatsStore: store}); err != nil {
+		t.Fatal(err)
+	}
+	if conversation, err = tenant.NewSessionWithConfig(SessionConfig{Name: "conv", StatsStore: store}); err != nil {
+		t.Fatal(err)
+	}
+	if got := tenant.Stats().BasicSearches; got != 2 {
+		t.Fatalf("expected 2 restored searches for the tenant, got %d", got)
+	}
+	if got := conversation.Stats().BasicSearches; got != 1 {
+		t.Fatalf("expected 1 restored search for the conversation, got %d", got)
+	}
+	// the root is not persisted: it gets the restored stats of its persisted sub sessions
+	if got := root.Stats().BasicSearches; got != 2 {
+		t.Fatalf("expected the root to include the 2 restored searches, got %d", got)
+	}
+}
+
+func TestSessionPersistenceCheckpoints(t *testing.T) {
+	store := &memoryStatsStore{}
+	root := newTestClient(t, &fakeAPI{}, ClientConfig{})
+	session, err := root.NewSessionWithConfig(SessionConfig{Name: "periodic", StatsStore: store, CheckpointInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSearch(t, session)
+	deadline := time.Now().Add(5 * time.Second)
+	for store.get("periodic").BasicSearches != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("no periodic checkpoint saved")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	// unnamed persisted sessions are rejected
+	if _, err = root.NewSessionWithConfig(SessionConfig{StatsStore: store}); err == nil {
+		t.Fatal("expected an error for an unnamed persisted session")
+	}
+}
+
+func TestSessionPersistenceDuplicateName(t *testing.T) {
+	store := &memoryStatsStore{}
+	root := newTestClient(t, &fakeAPI{}, ClientConfig{})
+	conf := SessionConfig{Name: "tenant", StatsStore: store, RateShare: 0.5}
+	tenant, err := root.NewSessionWithConfig(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// both would be saved under the same key
+	if _, err = root.NewSessionWithConfig(conf); err == nil {
+		t.Fatal("expected an error for a duplicated persisted session name")
+	}
+	// the rate share of the rejected session is released, and a not persisted session can share the name
+	if _, err = root.NewSessionWithConfig(SessionConfig{Name: "tenant", RateShare: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+	// the name is released once closed
+	if err = tenant.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = root.NewSessionWithConfig(conf); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type failingStatsStore struct{ memoryStatsStore }
+
+func (fss *failingStatsStore) Save(context.Context, string, Stats) error {
+	return errors.New("disk full")
+}
+
+func TestSessionCloseReportsCheckpointError(t *testing.T) {
+	root := newTestClient(t, &fakeAPI{}, ClientConfig{})
+	session, err := root.NewSessionWithConfig(SessionConfig{Name: "failing", StatsStore: &failingStatsStore{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = root.Close(); err == nil {
+		t.Fatal("expected the final checkpoint error of the sub session")
+	}
+	// closing twice is a no-op
+	if err = session.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
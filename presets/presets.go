@@ -0,0 +1,42 @@
+// Package presets loads tavily search presets from YAML (or JSON, YAML being a superset of JSON) configuration files.
+// It is a separate package so the programs not importing it do not build the YAML library in.
+package presets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hekmon/tavily/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Parse parses and validates search presets by name from YAML or JSON, eg:
+//
+//	fast-lookup:
+//	  max_results: 3
+//	  include_answer: basic
+//
+// Unknown settings are rejected.
+func Parse(data []byte) (presets tavily.SearchPresets, err error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err = decoder.Decode(&presets); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to unmarshal search presets: %w", err)
+	}
+	if err = presets.Validate(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Load reads and parses search presets from a YAML or JSON file, see Parse.
+func Load(path string) (presets tavily.SearchPresets, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search presets file: %w", err)
+	}
+	return Parse(data)
+}
@@ -0,0 +1,56 @@
+package presets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hekmon/tavily/v2"
+)
+
+func TestParse(t *testing.T) {
+	presets, err := Parse([]byte("quick:\n  max_results: 3\n  include_answer: basic\n  include_domains: [example.com]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	quick := presets["quick"]
+	if quick.MaxResults != 3 || quick.IncludeAnswer != tavily.SearchQueryIncludeAnswerBasic || len(quick.IncludeDomains) != 1 {
+		t.Errorf("unexpected presets: %+v", presets)
+	}
+	// JSON is YAML too
+	if presets, err = Parse([]byte(`{"news": {"topic": "news", "days": 2}}`)); err != nil || presets["news"].Days != 2 {
+		t.Errorf("unexpected JSON presets: %+v (%v)", presets, err)
+	}
+	if presets, err = Parse(nil); err != nil || len(presets) != 0 {
+		t.Errorf("expected no presets, got %+v (%v)", presets, err)
+	}
+}
+
+func TestParseRejections(t *testing.T) {
+	for name, data := range map[string]string{
+		"unknown setting": "quick:\n  max_result: 3\n",
+		"invalid preset":  "quick:\n  days: 3\n",
+		"invalid type":    "quick:\n  max_results: many\n",
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "presets.yaml")
+	if err := os.WriteFile(path, []byte("deep:\n  search_depth: advanced\n"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	presets, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if presets["deep"].SearchDepth != tavily.SearchQueryDepthAdvanced {
+		t.Errorf("unexpected presets: %+v", presets)
+	}
+	if _, err = Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected a missing file error")
+	}
+}
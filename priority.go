@@ -0,0 +1,158 @@
+package tavily
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Priority defines the order in which requests waiting for the rate limiter of a key are served.
+type Priority int
+
+const (
+	// PriorityLow is meant for background work (eg batch extractions).
+	PriorityLow Priority = -1
+	// PriorityNormal is the priority of the requests without an explicit one.
+	PriorityNormal Priority = 0
+	// PriorityHigh is meant for interactive, user facing requests.
+	PriorityHigh Priority = 1
+)
+
+// DefaultPriorityAging is the default waiting time after which a queued request gains one priority level.
+const DefaultPriorityAging = 5 * time.Second
+
+type priorityCtxKey struct{}
+
+// WithPriority returns a copy of ctx carrying the priority of the requests made with it.
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityCtxKey{}, priority)
+}
+
+// PriorityFromContext returns the priority carried by ctx, PriorityNormal if none.
+func PriorityFromContext(ctx context.Context) Priority {
+	if priority, ok := ctx.Value(priorityCtxKey{}).(Priority); ok {
+		return priority
+	}
+	return PriorityNormal
+}
+
+// scheduler serves the requests waiting for its limiter by priority. In order to prevent starvation,
+// a waiting request gains one priority level for each aging period spent in the queue.
+type scheduler struct {
+	limiter RateLimiter
+	aging   time.Duration
+	// State
+	access  sync.Mutex
+	queue   []*scheduledWaiter
+	running bool
+}
+
+type scheduledWaiter struct {
+	ctx      context.Context
+	priority Priority
+	enqueued time.Time
+	done     chan error
+}
+
+func newScheduler(limiter RateLimiter, aging time.Duration) *scheduler {
+	if aging <= 0 {
+		aging = DefaultPriorityAging
+	}
+	return &scheduler{
+		limiter: limiter,
+		aging:   aging,
+	}
+}
+
+// Wait blocks until the limiter allows the request, serving higher priority requests first.
+func (s *scheduler) Wait(ctx context.Context) error {
+	waiter := &scheduledWaiter{
+		ctx:      ctx,
+		priority: PriorityFromContext(ctx),
+		enqueued: time.Now(),
+		done:     make(chan error, 1),
+	}
+	s.access.Lock()
+	s.queue = append(s.queue, waiter)
+	if !s.running {
+		s.running = true
+		go s.dispatch()
+	}
+	s.access.Unlock()
+	select {
+	case err := <-waiter.done:
+		return err
+	case <-ctx.Done():
+		if !s.remove(waiter) {
+			// already dispatched: do not waste the limiter token if it has been obtained
+			return <-waiter.done
+		}
+		return ctx.Err()
+	}
+}
+
+// dispatch waits for the limiter on behalf of the queued requests, one at a time, until the queue is empty.
+func (s *scheduler) dispatch() {
+	for {
+		s.access.Lock()
+		waiter := s.pop()
+		if waiter == nil {
+			s.running = false
+			s.access.Unlock()
+			return
+		}
+		s.access.Unlock()
+		if err := waiter.ctx.Err(); err != nil {
+			// do not take a limiter token for a canceled request
+			waiter.done <- err
+			continue
+		}
+		waiter.done <- s.limiter.Wait(waiter.ctx)
+	}
+}
+
+// pop removes and returns the waiter with the highest effective priority. Caller must hold the lock.
+func (s *scheduler) pop() (selected *scheduledWaiter) {
+	if len(s.queue) == 0 {
+		return nil
+	}
+	now := time.Now()
+	var (
+		selectedIndex int
+		selectedScore float64
+	)
+	for index, waiter := range s.queue {
+		score := float64(waiter.priority) + float64(now.Sub(waiter.enqueued))/float64(s.aging)
+		if selected == nil || score > selectedScore {
+			selected = waiter
+			selectedIndex = index
+			selectedScore = score
+		}
+	}
+	s.queue = append(s.queue[:selectedIndex], s.queue[selectedIndex+1:]...)
+	return
+}
+
+func (s *scheduler) remove(waiter *scheduledWaiter) (found bool) {
+	s.access.Lock()
+	defer s.access.Unlock()
+	for index, queued := range s.queue {
+		if queued == waiter {
+			s.queue = append(s.queue[:index], s.queue[index+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// chainedLimiter waits for each of its limiters in turn.
+type chainedLimiter []RateLimiter
+
+func (cl chainedLimiter) Wait(ctx context.Context) error {
+	for _, limiter := range cl {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
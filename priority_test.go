@@ -0,0 +1,162 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// gateLimiter is a RateLimiter allowing one request per token sent on its channel.
+type gateLimiter struct {
+	waiting chan struct{}
+	tokens  chan struct{}
+}
+
+func newGateLimiter() *gateLimiter {
+	return &gateLimiter{
+		waiting: make(chan struct{}, 16),
+		tokens:  make(chan struct{}),
+	}
+}
+
+func (gl *gateLimiter) Wait(ctx context.Context) error {
+	gl.waiting <- struct{}{}
+	select {
+	case <-gl.tokens:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *scheduler) queued() int {
+	s.access.Lock()
+	defer s.access.Unlock()
+	return len(s.queue)
+}
+
+func waitQueued(t *testing.T, s *scheduler, expected int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for s.queued() != expected {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d queued requests, got %d", expected, s.queued())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSchedulerServesByPriority(t *testing.T) {
+	gate := newGateLimiter()
+	s := newScheduler(gate, time.Hour)
+	served := make(chan Priority, 4)
+	wait := func(priority Priority) {
+		if err := s.Wait(WithPriority(context.Background(), priority)); err != nil {
+			t.Error(err)
+		}
+		served <- priority
+	}
+	// the first request holds the limiter while the others queue
+	go wait(PriorityNormal)
+	<-gate.waiting
+	go wait(PriorityLow)
+	waitQueued(t, s, 1)
+	go wait(PriorityNormal)
+	waitQueued(t, s, 2)
+	go wait(PriorityHigh)
+	waitQueued(t, s, 3)
+	var order []Priority
+	for range 4 {
+		gate.tokens <- struct{}{}
+		order = append(order, <-served)
+		if len(order) < 4 {
+			<-gate.waiting
+		}
+	}
+	expected := []Priority{PriorityNormal, PriorityHigh, PriorityNormal, PriorityLow}
+	for index := range expected {
+		if order[index] != expected[index] {
+			t.Fatalf("expected %v, got %v", expected, order)
+		}
+	}
+}
+
+func TestSchedulerAging(t *testing.T) {
+	gate := newGateLimiter()
+	s := newScheduler(gate, 10*time.Millisecond)
+	served := make(chan Priority, 3)
+	wait := func(priority Priority) {
+		if err := s.Wait(WithPriority(context.Background(), priority)); err != nil {
+			t.Error(err)
+		}
+		served <- priority
+	}
+	go wait(PriorityNormal)
+	<-gate.waiting
+	go wait(PriorityLow)
+	waitQueued(t, s, 1)
+	// the low priority request has waited for several aging periods: it goes first
+	time.Sleep(50 * time.Millisecond)
+	go wait(PriorityHigh)
+	waitQueued(t, s, 2)
+	gate.tokens <- struct{}{}
+	<-served
+	<-gate.waiting
+	gate.tokens <- struct{}{}
+	if got := <-served; got != PriorityLow {
+		t.Fatalf("expected the aged low priority request to be served first, got %d", got)
+	}
+	<-gate.waiting
+	gate.tokens <- struct{}{}
+	<-served
+}
+
+func TestSchedulerCancelledWaiter(t *testing.T) {
+	gate := newGateLimiter()
+	s := newScheduler(gate, time.Hour)
+	go s.Wait(context.Background())
+	<-gate.waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() { errs <- s.Wait(ctx) }()
+	waitQueued(t, s, 1)
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancellation error, got %v", err)
+	}
+	if s.queued() != 0 {
+		t.Fatal("expected the cancelled request to leave the queue")
+	}
+	gate.tokens <- struct{}{}
+}
+
+// funcLimiter is a RateLimiter calling its function.
+type funcLimiter func(ctx context.Context) error
+
+func (fl funcLimiter) Wait(ctx context.Context) error {
+	return fl(ctx)
+}
+
+func TestSchedulerTokenObtainedBeforeCancellation(t *testing.T) {
+	for range 100 {
+		ctx, cancel := context.WithCancel(context.Background())
+		// the caller gives up right after the limiter allowed its request
+		s := newScheduler(funcLimiter(func(context.Context) error {
+			cancel()
+			return nil
+		}), time.Hour)
+		if err := s.Wait(ctx); err != nil {
+			t.Fatalf("expected the obtained token to be used, got %v", err)
+		}
+	}
+}
+
+func TestPriorityFromContext(t *testing.T) {
+	if PriorityFromContext(context.Background()) != PriorityNormal {
+		t.Fatal("expected the normal priority by default")
+	}
+	if PriorityFromContext(WithPriority(context.Background(), PriorityHigh)) != PriorityHigh {
+		t.Fatal("expected the context priority")
+	}
+}
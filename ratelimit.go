@@ -0,0 +1,88 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter controls the pace of the requests sent to the API. *rate.Limiter from golang.org/x/time/rate satisfies it.
+type RateLimiter interface {
+	// Wait blocks until a request is allowed or ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// RateLimit represents a contractual rate limit.
+type RateLimit struct {
+	ReqPerMinute int // Requests per minute allowed, required.
+	Burst        int // Optional maximum number of requests that can be sent at once. Default is ReqPerMinute.
+}
+
+func (rl RateLimit) validate() error {
+	switch {
+	case rl.ReqPerMinute <= 0:
+		return errors.New("requests per minute must be a positive integer")
+	case rl.Burst < 0:
+		return errors.New("burst must be a non-negative integer")
+	}
+	return nil
+}
+
+func (rl RateLimit) burst() int {
+	if rl.Burst == 0 {
+		return rl.ReqPerMinute
+	}
+	return rl.Burst
+}
+
+// interval returns the time between two requests at a steady pace.
+func (rl RateLimit) interval() time.Duration {
+	return time.Minute / time.Duration(rl.ReqPerMinute)
+}
+
+// newRateLimiter returns a local limiter if sharedStore is nil, a shared one named name otherwise.
+func newRateLimiter(limit RateLimit, sharedStore SharedRateLimiterStore, name string) (RateLimiter, error) {
+	if sharedStore != nil {
+		return NewSharedRateLimiter(sharedStore, name, limit)
+	}
+	return rate.NewLimiter(rate.Limit(limit.ReqPerMinute)/rate.Limit(time.Minute/time.Second), limit.burst()), nil
+}
+
+// shareLimiter limits the requests to a share of a rate which may change over time (eg the one of a parent session).
+type shareLimiter struct {
+	share     float64
+	totalRate func() float64 // req/min
+	limiter   *rate.Limiter
+	access    sync.Mutex
+	current   float64 // req/min
+}
+
+func newShareLimiter(share float64, totalRate func() float64) *shareLimiter {
+	sl := &shareLimiter{
+		share:     share,
+		totalRate: totalRate,
+		limiter:   rate.NewLimiter(0, 1),
+	}
+	sl.update()
+	return sl
+}
+
+// update adjusts the limiter to the current share of the total rate. Its burst follows the rate, as for RateLimit.
+func (sl *shareLimiter) update() {
+	reqPerMinute := max(1, sl.share*sl.totalRate())
+	sl.access.Lock()
+	defer sl.access.Unlock()
+	if reqPerMinute != sl.current {
+		sl.current = reqPerMinute
+		sl.limiter.SetLimit(reqPerMinuteToLimit(reqPerMinute))
+		sl.limiter.SetBurst(max(1, int(reqPerMinute)))
+	}
+}
+
+func (sl *shareLimiter) Wait(ctx context.Context) error {
+	sl.update()
+	return sl.limiter.Wait(ctx)
+}
@@ -0,0 +1,202 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// AdaptiveRateLimit configures the adaptive rate limiting of the API keys: when the API throttles the client anyway
+// (429 responses or rate limit headers), the effective rate of the key is lowered and then slowly recovered (AIMD).
+// The effective rate of each key is available thru the client Keys() method.
+type AdaptiveRateLimit struct {
+	DecreaseFactor   float64       // Factor applied to the effective rate on each 429 response. Default is 0.5.
+	MinReqPerMinute  float64       // The effective rate will never go below this value. Default is 1.
+	IncreaseStep     float64       // Requests per minute added to the effective rate at each recovery step. Default is 5% of the key rate.
+	RecoveryInterval time.Duration // Minimum time without throttling between two recovery steps. Default is 10s.
+	// Time window of the limit announced by the X-RateLimit-Limit header, which temporarily lowers the effective rate ceiling
+	// (back to the key rate once the header stops announcing a lower limit for a recovery interval). Default is one minute,
+	// negative ignores the header.
+	LimitHeaderWindow time.Duration
+}
+
+const (
+	defaultAdaptiveDecreaseFactor    = 0.5
+	defaultAdaptiveMinReqPerMinute   = 1
+	defaultAdaptiveIncreaseRatio     = 0.05
+	defaultAdaptiveRecoveryInterval  = 10 * time.Second
+	defaultAdaptiveLimitHeaderWindow = time.Minute
+)
+
+func (arl AdaptiveRateLimit) validate() error {
+	switch {
+	case arl.DecreaseFactor < 0 || arl.DecreaseFactor >= 1:
+		return errors.New("decrease factor must be within [0, 1[")
+	case arl.MinReqPerMinute < 0:
+		return errors.New("min requests per minute must be non-negative")
+	case arl.IncreaseStep < 0:
+		return errors.New("increase step must be non-negative")
+	case arl.RecoveryInterval < 0:
+		return errors.New("recovery interval must be non-negative")
+	}
+	return nil
+}
+
+// adaptiveLimiter gates the requests of a key in front of its own rate limiter.
+type adaptiveLimiter struct {
+	conf  AdaptiveRateLimit
+	limit RateLimit // configured key limit
+	gate  *rate.Limiter
+	// State
+	access          sync.Mutex
+	ceiling         float64 // req/min, lowered by the rate limit headers
+	lastLimitHeader time.Time
+	current         float64 // req/min
+	pausedUntil     time.Time
+	lastDecrease    time.Time
+	lastIncrease    time.Time
+}
+
+func newAdaptiveLimiter(conf AdaptiveRateLimit, limit RateLimit) *adaptiveLimiter {
+	if conf.DecreaseFactor == 0 {
+		conf.DecreaseFactor = defaultAdaptiveDecreaseFactor
+	}
+	if conf.MinReqPerMinute == 0 {
+		conf.MinReqPerMinute = defaultAdaptiveMinReqPerMinute
+	}
+	if conf.IncreaseStep == 0 {
+		conf.IncreaseStep = math.Max(1, float64(limit.ReqPerMinute)*defaultAdaptiveIncreaseRatio)
+	}
+	if conf.RecoveryInterval == 0 {
+		conf.RecoveryInterval = defaultAdaptiveRecoveryInterval
+	}
+	if conf.LimitHeaderWindow == 0 {
+		conf.LimitHeaderWindow = defaultAdaptiveLimitHeaderWindow
+	}
+	ceiling := float64(limit.ReqPerMinute)
+	return &adaptiveLimiter{
+		conf:    conf,
+		limit:   limit,
+		gate:    rate.NewLimiter(reqPerMinuteToLimit(ceiling), limit.burst()),
+		ceiling: ceiling,
+		current: ceiling,
+	}
+}
+
+func (al *adaptiveLimiter) Wait(ctx context.Context) error {
+	al.access.Lock()
+	pause := time.Until(al.pausedUntil)
+	al.access.Unlock()
+	if pause > 0 {
+		timer := time.NewTimer(pause)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return al.gate.Wait(ctx)
+}
+
+// effectiveReqPerMinute returns the current effective rate.
+func (al *adaptiveLimiter) effectiveReqPerMinute() float64 {
+	al.access.Lock()
+	defer al.access.Unlock()
+	return al.current
+}
+
+// observe adjusts the effective rate based on an API response.
+func (al *adaptiveLimiter) observe(statusCode int, header http.Header) {
+	now := time.Now()
+	al.access.Lock()
+	defer al.access.Unlock()
+	// Rate limit headers
+	if limit, err := strconv.ParseFloat(header.Get("X-RateLimit-Limit"), 64); err == nil && limit > 0 && al.conf.LimitHeaderWindow > 0 {
+		reqPerMinute := limit * float64(time.Minute) / float64(al.conf.LimitHeaderWindow)
+		if reqPerMinute < float64(al.limit.ReqPerMinute) {
+			al.ceiling = reqPerMinute
+			al.lastLimitHeader = now
+			if al.current > al.ceiling {
+				al.setCurrent(al.ceiling)
+			}
+		}
+	}
+	if al.ceiling < float64(al.limit.ReqPerMinute) && now.Sub(al.lastLimitHeader) >= al.conf.RecoveryInterval {
+		// the lower limit is not announced anymore
+		al.ceiling = float64(al.limit.ReqPerMinute)
+	}
+	if remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining")); err == nil && remaining <= 0 {
+		if reset, ok := parseRateLimitReset(header.Get("X-RateLimit-Reset"), now); ok {
+			al.pauseUntil(reset)
+		}
+	}
+	// Throttled
+	if statusCode == http.StatusTooManyRequests {
+		if retryAt, ok := parseRetryAfter(header.Get("Retry-After"), now); ok {
+			al.pauseUntil(retryAt)
+		}
+		// only decrease once per burst of 429 responses (requests already in flight were sent at the previous rate)
+		if now.Sub(al.lastDecrease) >= al.conf.RecoveryInterval/2 {
+			al.setCurrent(math.Max(al.conf.MinReqPerMinute, al.current*al.conf.DecreaseFactor))
+			al.lastDecrease = now
+		}
+		return
+	}
+	// Slow recovery
+	if al.current < al.ceiling &&
+		now.Sub(al.lastDecrease) >= al.conf.RecoveryInterval && now.Sub(al.lastIncrease) >= al.conf.RecoveryInterval {
+		al.setCurrent(math.Min(al.ceiling, al.current+al.conf.IncreaseStep))
+		al.lastIncrease = now
+	}
+}
+
+// setCurrent sets the effective rate, scaling the burst accordingly so an idle key can not release its full configured
+// burst while it is throttled.
+func (al *adaptiveLimiter) setCurrent(reqPerMinute float64) {
+	al.current = reqPerMinute
+	al.gate.SetLimit(reqPerMinuteToLimit(reqPerMinute))
+	al.gate.SetBurst(max(1, int(float64(al.limit.burst())*reqPerMinute/float64(al.limit.ReqPerMinute))))
+}
+
+func (al *adaptiveLimiter) pauseUntil(t time.Time) {
+	if t.After(al.pausedUntil) {
+		al.pausedUntil = t
+	}
+}
+
+func reqPerMinuteToLimit(reqPerMinute float64) rate.Limit {
+	return rate.Limit(reqPerMinute / 60)
+}
+
+// parseRetryAfter parses a Retry-After header value, either in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		return now.Add(time.Duration(seconds * float64(time.Second))), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return date, true
+	}
+	return time.Time{}, false
+}
+
+// parseRateLimitReset parses a X-RateLimit-Reset header value, either as a Unix timestamp or as a number of seconds.
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}, false
+	}
+	if seconds > 1e9 {
+		return time.Unix(0, int64(seconds*float64(time.Second))), true
+	}
+	return now.Add(time.Duration(seconds * float64(time.Second))), true
+}
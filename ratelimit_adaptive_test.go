@@ -0,0 +1,139 @@
+package tavily
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newTestAdaptiveLimiter() *adaptiveLimiter {
+	return newAdaptiveLimiter(AdaptiveRateLimit{
+		IncreaseStep:     100,
+		RecoveryInterval: time.Second,
+	}, RateLimit{ReqPerMinute: 1000, Burst: 1000})
+}
+
+// elapse moves the adaptive limiter state back in time, as if d had elapsed.
+func (al *adaptiveLimiter) elapse(d time.Duration) {
+	al.access.Lock()
+	defer al.access.Unlock()
+	al.lastDecrease = al.lastDecrease.Add(-d)
+	al.lastIncrease = al.lastIncrease.Add(-d)
+	al.lastLimitHeader = al.lastLimitHeader.Add(-d)
+}
+
+func TestAdaptiveDecreaseAndRecovery(t *testing.T) {
+	al := newTestAdaptiveLimiter()
+	al.observe(http.StatusTooManyRequests, http.Header{})
+	if got := al.effectiveReqPerMinute(); got != 500 {
+		t.Fatalf("expected the rate to be halved, got %g", got)
+	}
+	if got := al.gate.Burst(); got != 500 {
+		t.Fatalf("expected the burst to be scaled down with the rate, got %d", got)
+	}
+	// a burst of 429 responses only decreases once
+	al.observe(http.StatusTooManyRequests, http.Header{})
+	if got := al.effectiveReqPerMinute(); got != 500 {
+		t.Fatalf("expected a single decrease, got %g", got)
+	}
+	// no recovery before the recovery interval
+	al.observe(http.StatusOK, http.Header{})
+	if got := al.effectiveReqPerMinute(); got != 500 {
+		t.Fatalf("expected no recovery yet, got %g", got)
+	}
+	al.elapse(time.Second)
+	al.observe(http.StatusOK, http.Header{})
+	if got := al.effectiveReqPerMinute(); got != 600 {
+		t.Fatalf("expected a recovery step, got %g", got)
+	}
+	if got := al.gate.Burst(); got != 600 {
+		t.Fatalf("expected the burst to recover with the rate, got %d", got)
+	}
+	for range 10 {
+		al.elapse(time.Second)
+		al.observe(http.StatusOK, http.Header{})
+	}
+	if got := al.effectiveReqPerMinute(); got != 1000 {
+		t.Fatalf("expected a full recovery, got %g", got)
+	}
+}
+
+func TestAdaptiveMinimumRate(t *testing.T) {
+	al := newAdaptiveLimiter(AdaptiveRateLimit{MinReqPerMinute: 100, DecreaseFactor: 0.1}, RateLimit{ReqPerMinute: 500})
+	for range 3 {
+		al.elapse(time.Minute)
+		al.observe(http.StatusTooManyRequests, http.Header{})
+	}
+	if got := al.effectiveReqPerMinute(); got != 100 {
+		t.Fatalf("expected the rate to stop at its minimum, got %g", got)
+	}
+	if got := al.gate.Burst(); got != 100 {
+		t.Fatalf("expected a burst of 100, got %d", got)
+	}
+}
+
+func TestAdaptiveLimitHeader(t *testing.T) {
+	al := newTestAdaptiveLimiter()
+	// announced limit lower than the key one
+	al.observe(http.StatusOK, http.Header{"X-Ratelimit-Limit": []string{"200"}})
+	if got := al.effectiveReqPerMinute(); got != 200 {
+		t.Fatalf("expected the rate to follow the announced limit, got %g", got)
+	}
+	// the ceiling recovers once the lower limit is not announced anymore
+	al.elapse(time.Second)
+	al.observe(http.StatusOK, http.Header{})
+	if got := al.effectiveReqPerMinute(); got != 300 {
+		t.Fatalf("expected the rate to recover above the previous announced limit, got %g", got)
+	}
+	// announced limits higher than the key one are ignored
+	al.observe(http.StatusOK, http.Header{"X-Ratelimit-Limit": []string{"5000"}})
+	if al.ceiling != 1000 {
+		t.Fatalf("expected the ceiling to stay at the key rate, got %g", al.ceiling)
+	}
+}
+
+func TestAdaptiveLimitHeaderWindow(t *testing.T) {
+	al := newAdaptiveLimiter(AdaptiveRateLimit{LimitHeaderWindow: time.Second}, RateLimit{ReqPerMinute: 1000})
+	al.observe(http.StatusOK, http.Header{"X-Ratelimit-Limit": []string{"5"}})
+	if got := al.effectiveReqPerMinute(); got != 300 {
+		t.Fatalf("expected 5 req/s to be 300 req/min, got %g", got)
+	}
+	ignoring := newAdaptiveLimiter(AdaptiveRateLimit{LimitHeaderWindow: -1}, RateLimit{ReqPerMinute: 1000})
+	ignoring.observe(http.StatusOK, http.Header{"X-Ratelimit-Limit": []string{"5"}})
+	if got := ignoring.effectiveReqPerMinute(); got != 1000 {
+		t.Fatalf("expected the header to be ignored, got %g", got)
+	}
+}
+
+func TestAdaptivePauses(t *testing.T) {
+	al := newTestAdaptiveLimiter()
+	before := time.Now()
+	al.observe(http.StatusTooManyRequests, http.Header{"Retry-After": []string{"2"}})
+	if pause := al.pausedUntil.Sub(before); pause < 2*time.Second || pause > 3*time.Second {
+		t.Fatalf("expected a 2s pause, got %s", pause)
+	}
+	al = newTestAdaptiveLimiter()
+	al.observe(http.StatusOK, http.Header{"X-Ratelimit-Remaining": []string{"0"}, "X-Ratelimit-Reset": []string{"5"}})
+	if pause := al.pausedUntil.Sub(before); pause < 5*time.Second || pause > 6*time.Second {
+		t.Fatalf("expected a 5s pause, got %s", pause)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for value, expected := range map[string]time.Time{
+		"1.5":                           now.Add(1500 * time.Millisecond),
+		"Wed, 01 Jan 2025 00:01:00 GMT": now.Add(time.Minute),
+	} {
+		got, ok := parseRetryAfter(value, now)
+		if !ok || !got.Equal(expected) {
+			t.Errorf("%q: expected %s, got %s (%v)", value, expected, got, ok)
+		}
+	}
+	if _, ok := parseRetryAfter("soon", now); ok {
+		t.Error("expected an invalid value to be rejected")
+	}
+	if got, ok := parseRateLimitReset("1735689660", now); !ok || !got.Equal(now.Add(time.Minute)) {
+		t.Errorf("expected a Unix timestamp reset, got %s", got)
+	}
+}
@@ -0,0 +1,209 @@
+package tavily
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SharedRateLimiterStore holds the state of rate limiters shared by several clients, possibly across processes.
+type SharedRateLimiterStore interface {
+	// Take tries to consume one request from the limiter identified by name. It returns 0 if the request is allowed,
+	// otherwise the duration to wait before trying again.
+	Take(ctx context.Context, name string, limit RateLimit) (retryAfter time.Duration, err error)
+}
+
+// SharedRateLimiterName returns the name used by the client to identify the shared rate limiter of an API key.
+// The key itself is never sent to the store.
+func SharedRateLimiterName(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return "tavily:" + hex.EncodeToString(sum[:12])
+}
+
+// SharedRateLimiter is a RateLimiter whose state lives in a SharedRateLimiterStore.
+type SharedRateLimiter struct {
+	store SharedRateLimiterStore
+	name  string
+	limit RateLimit
+}
+
+// NewSharedRateLimiter returns a rate limiter enforcing limit for all the limiters sharing the same store and name.
+func NewSharedRateLimiter(store SharedRateLimiterStore, name string, limit RateLimit) (*SharedRateLimiter, error) {
+	if store == nil {
+		return nil, errors.New("store is required")
+	}
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if err := limit.validate(); err != nil {
+		return nil, fmt.Errorf("invalid rate limit: %w", err)
+	}
+	return &SharedRateLimiter{
+		store: store,
+		name:  name,
+		limit: limit,
+	}, nil
+}
+
+// Wait blocks until a request is allowed by the shared state or ctx is done.
+func (srl *SharedRateLimiter) Wait(ctx context.Context) error {
+	var timer *time.Timer
+	for {
+		retryAfter, err := srl.store.Take(ctx, srl.name, srl.limit)
+		if err != nil {
+			return fmt.Errorf("failed to consult shared rate limiter store: %w", err)
+		}
+		if retryAfter <= 0 {
+			return nil
+		}
+		if timer == nil {
+			timer = time.NewTimer(retryAfter)
+			defer timer.Stop()
+		} else {
+			timer.Reset(retryAfter)
+		}
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+/*
+	In memory store
+*/
+
+// MemoryRateLimiterStore is an in process SharedRateLimiterStore. It allows several clients of the same process
+// to share their limits and can be used as a fake of a distributed store for testing purposes.
+type MemoryRateLimiterStore struct {
+	access    sync.Mutex
+	tats      map[string]time.Time // theoretical arrival times (GCRA)
+	lastSweep time.Time
+}
+
+// memoryStoreSweepInterval is the minimum time between two cleanups of the expired states of a MemoryRateLimiterStore.
+const memoryStoreSweepInterval = time.Minute
+
+// NewMemoryRateLimiterStore returns an empty in memory store.
+func NewMemoryRateLimiterStore() *MemoryRateLimiterStore {
+	return &MemoryRateLimiterStore{
+		tats: make(map[string]time.Time),
+	}
+}
+
+// Take implements SharedRateLimiterStore using the generic cell rate algorithm, as the Redis store does.
+func (mrls *MemoryRateLimiterStore) Take(ctx context.Context, name string, limit RateLimit) (retryAfter time.Duration, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	now := time.Now()
+	interval := limit.interval()
+	mrls.access.Lock()
+	defer mrls.access.Unlock()
+	tat := mrls.tats[name]
+	if tat.Before(now) {
+		tat = now
+	}
+	newTAT := tat.Add(interval)
+	if allowAt := newTAT.Add(-interval * time.Duration(limit.burst())); now.Before(allowAt) {
+		return allowAt.Sub(now), nil
+	}
+	mrls.tats[name] = newTAT
+	// periodic cleanup of the expired states, amortized over the calls
+	if now.Sub(mrls.lastSweep) >= memoryStoreSweepInterval {
+		mrls.sweep(now)
+	}
+	return 0, nil
+}
+
+// sweep removes the states expired at now (the ones equivalent to a full bucket). Caller must hold the lock.
+func (mrls *MemoryRateLimiterStore) sweep(now time.Time) {
+	for name, tat := range mrls.tats {
+		if tat.Before(now) {
+			delete(mrls.tats, name)
+		}
+	}
+	mrls.lastSweep = now
+}
+
+/*
+	Redis store
+*/
+
+// RedisEvaluator is the minimal interface a Redis client must satisfy to be used as a shared rate limiter store.
+// It allows to use any Redis (or Redis protocol compatible) client library without depending on it.
+type RedisEvaluator interface {
+	// Eval runs a Lua script on the server and returns its result.
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// RedisEvalFunc allows to use a function as a RedisEvaluator. For example with go-redis:
+//
+//	tavily.RedisEvalFunc(func(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+//		return rdb.Eval(ctx, script, keys, args...).Result()
+//	})
+type RedisEvalFunc func(ctx context.Context, script string, keys []string, args ...any) (any, error)
+
+// Eval implements RedisEvaluator.
+func (ref RedisEvalFunc) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+	return ref(ctx, script, keys, args...)
+}
+
+// redisGCRAScript implements the generic cell rate algorithm using the server clock, so replicas clocks skew does not matter.
+// Times are in microseconds. Returns the number of microseconds to wait, 0 if the request is allowed. Requires Redis >= 5.
+const redisGCRAScript = `
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+local tat = tonumber(redis.call('GET', KEYS[1])) or now
+if tat < now then
+	tat = now
+end
+local new_tat = tat + interval
+local allow_at = new_tat - interval * burst
+if now < allow_at then
+	return allow_at - now
+end
+redis.call('SET', KEYS[1], new_tat, 'PX', math.ceil((new_tat - now) / 1000))
+return 0
+`
+
+// RedisRateLimiterStore is a SharedRateLimiterStore backed by a Redis compatible server.
+type RedisRateLimiterStore struct {
+	client RedisEvaluator
+	prefix string
+}
+
+// NewRedisRateLimiterStore returns a store using client. prefix is prepended to the limiters names to build the Redis keys.
+func NewRedisRateLimiterStore(client RedisEvaluator, prefix string) *RedisRateLimiterStore {
+	return &RedisRateLimiterStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Take implements SharedRateLimiterStore.
+func (rrls *RedisRateLimiterStore) Take(ctx context.Context, name string, limit RateLimit) (retryAfter time.Duration, err error) {
+	result, err := rrls.client.Eval(ctx, redisGCRAScript, []string{rrls.prefix + name},
+		limit.interval().Microseconds(), limit.burst(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to evaluate script: %w", err)
+	}
+	var waitMicroseconds int64
+	switch typed := result.(type) {
+	case int64:
+		waitMicroseconds = typed
+	case int:
+		waitMicroseconds = int64(typed)
+	default:
+		return 0, fmt.Errorf("unexpected script result type %T", result)
+	}
+	return time.Duration(waitMicroseconds) * time.Microsecond, nil
+}
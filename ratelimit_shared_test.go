@@ -0,0 +1,227 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is a RedisEvaluator running the GCRA script of RedisRateLimiterStore in Go against an in memory
+// state and a controllable clock, checking the arguments the store sends to the server.
+type fakeRedis struct {
+	t      *testing.T
+	access sync.Mutex
+	now    int64 // server clock, microseconds
+	values map[string]int64
+	expiry map[string]int64 // microseconds
+	result func(wait int64) any
+	err    error
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	return &fakeRedis{
+		t:      t,
+		now:    1_700_000_000_000_000,
+		values: make(map[string]int64),
+		expiry: make(map[string]int64),
+	}
+}
+
+func (fr *fakeRedis) advance(d time.Duration) {
+	fr.access.Lock()
+	defer fr.access.Unlock()
+	fr.now += d.Microseconds()
+}
+
+func (fr *fakeRedis) Eval(_ context.Context, script string, keys []string, args ...any) (any, error) {
+	fr.access.Lock()
+	defer fr.access.Unlock()
+	if fr.err != nil {
+		return nil, fr.err
+	}
+	if script != redisGCRAScript {
+		fr.t.Fatal("unexpected script")
+	}
+	if len(keys) != 1 || len(args) != 2 {
+		fr.t.Fatalf("unexpected keys %v or args %v", keys, args)
+	}
+	interval, ok1 := args[0].(int64)
+	burst, ok2 := args[1].(int)
+	if !ok1 || !ok2 {
+		fr.t.Fatalf("unexpected args types %T and %T", args[0], args[1])
+	}
+	// GET with expiration
+	key := keys[0]
+	tat, found := fr.values[key]
+	if !found || fr.expiry[key] <= fr.now {
+		tat = fr.now
+	}
+	if tat < fr.now {
+		tat = fr.now
+	}
+	newTAT := tat + interval
+	var wait int64
+	if allowAt := newTAT - interval*int64(burst); fr.now < allowAt {
+		wait = allowAt - fr.now
+	} else {
+		fr.values[key] = newTAT
+		fr.expiry[key] = fr.now + ((newTAT-fr.now+999)/1000)*1000 // PX in milliseconds, rounded up
+	}
+	if fr.result != nil {
+		return fr.result(wait), nil
+	}
+	return wait, nil
+}
+
+func TestRedisRateLimiterStore(t *testing.T) {
+	redis := newFakeRedis(t)
+	store := NewRedisRateLimiterStore(redis, "test:")
+	limit := RateLimit{ReqPerMinute: 60, Burst: 2}
+	ctx := context.Background()
+	take := func() time.Duration {
+		t.Helper()
+		retryAfter, err := store.Take(ctx, "key", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return retryAfter
+	}
+	// burst
+	if take() != 0 || take() != 0 {
+		t.Fatal("expected the burst to be allowed")
+	}
+	if retryAfter := take(); retryAfter != time.Second {
+		t.Fatalf("expected to wait 1s, got %s", retryAfter)
+	}
+	// steady pace
+	redis.advance(time.Second)
+	if take() != 0 {
+		t.Fatal("expected a request to be allowed after the interval")
+	}
+	if retryAfter := take(); retryAfter != time.Second {
+		t.Fatalf("expected to wait 1s, got %s", retryAfter)
+	}
+	// full bucket after a long idle period
+	redis.advance(time.Hour)
+	if take() != 0 || take() != 0 || take() == 0 {
+		t.Fatal("expected only the burst to be allowed after an idle period")
+	}
+	if _, found := redis.values["test:key"]; !found {
+		t.Fatal("expected the key to be prefixed")
+	}
+}
+
+func TestRedisRateLimiterStoreResults(t *testing.T) {
+	redis := newFakeRedis(t)
+	store := NewRedisRateLimiterStore(redis, "")
+	limit := RateLimit{ReqPerMinute: 60, Burst: 1}
+	// some clients return int instead of int64
+	redis.result = func(wait int64) any { return int(wait) }
+	if _, err := store.Take(context.Background(), "key", limit); err != nil {
+		t.Fatal(err)
+	}
+	redis.result = func(int64) any { return "0" }
+	if _, err := store.Take(context.Background(), "key", limit); err == nil {
+		t.Fatal("expected an error for an unexpected result type")
+	}
+	redis.err = errors.New("connection refused")
+	if _, err := store.Take(context.Background(), "key", limit); !errors.Is(err, redis.err) {
+		t.Fatalf("expected the evaluation error, got %v", err)
+	}
+}
+
+func TestSharedRateLimiterSharesState(t *testing.T) {
+	redis := newFakeRedis(t)
+	store := NewRedisRateLimiterStore(redis, "")
+	limit := RateLimit{ReqPerMinute: 60, Burst: 1}
+	first, err := NewSharedRateLimiter(store, "shared", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewSharedRateLimiter(store, "shared", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = first.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// the fake clock does not move: the second limiter must wait until ctx is done
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err = second.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the second limiter to wait for the shared state, got %v", err)
+	}
+	for name, test := range map[string]struct {
+		store SharedRateLimiterStore
+		name  string
+		limit RateLimit
+	}{
+		"no store": {nil, "shared", limit},
+		"no name":  {store, "", limit},
+		"no rate":  {store, "shared", RateLimit{}},
+	} {
+		if _, err = NewSharedRateLimiter(test.store, test.name, test.limit); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestMemoryRateLimiterStore(t *testing.T) {
+	store := NewMemoryRateLimiterStore()
+	limit := RateLimit{ReqPerMinute: 60, Burst: 2}
+	ctx := context.Background()
+	for range 2 {
+		if retryAfter, err := store.Take(ctx, "a", limit); err != nil || retryAfter != 0 {
+			t.Fatalf("expected the burst to be allowed, got %s %v", retryAfter, err)
+		}
+	}
+	retryAfter, err := store.Take(ctx, "a", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retryAfter <= 900*time.Millisecond || retryAfter > time.Second {
+		t.Fatalf("expected to wait about 1s, got %s", retryAfter)
+	}
+	// limiters are independent
+	if retryAfter, _ = store.Take(ctx, "b", limit); retryAfter != 0 {
+		t.Fatal("expected another limiter to be allowed")
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err = store.Take(cancelled, "c", limit); err == nil {
+		t.Fatal("expected an error for a cancelled context")
+	}
+}
+
+func TestMemoryRateLimiterStoreSweep(t *testing.T) {
+	store := NewMemoryRateLimiterStore()
+	limit := RateLimit{ReqPerMinute: 60}
+	now := time.Now()
+	store.tats["expired"] = now.Add(-time.Second)
+	store.lastSweep = now
+	if _, err := store.Take(context.Background(), "a", limit); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := store.tats["expired"]; !found {
+		t.Fatal("expected no sweep before the sweep interval")
+	}
+	store.lastSweep = now.Add(-memoryStoreSweepInterval)
+	if _, err := store.Take(context.Background(), "a", limit); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := store.tats["expired"]; found {
+		t.Fatal("expected the expired state to be swept")
+	}
+	if _, found := store.tats["a"]; !found {
+		t.Fatal("expected the live state to be kept")
+	}
+}
+
+func TestSharedRateLimiterName(t *testing.T) {
+	name := SharedRateLimiterName("tvly-dev-secret")
+	if name == SharedRateLimiterName("tvly-dev-other") || len(name) != len("tavily:")+24 {
+		t.Fatalf("unexpected name %q", name)
+	}
+}
@@ -0,0 +1,258 @@
+package tavily
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"slices"
+
+	"github.com/hekmon/tavily/v2/urlcanon"
+)
+
+// ReplayConfig configures the replay of audit records.
+type ReplayConfig struct {
+	// Optional API credits budget of the replay. A record is not replayed if its worst case cost would exceed
+	// the remaining budget, and the replay stops.
+	MaxCredits float64
+	// Optional minimum absolute score difference for a result score change to be reported.
+	ScoreTolerance float64
+	// Also replay the records of the calls which failed originally.
+	IncludeFailed bool
+}
+
+// ReplayReport is the result of a replay.
+type ReplayReport struct {
+	Entries         []ReplayEntry
+	Replayed        int     // Number of records replayed.
+	Skipped         int     // Number of records skipped (failed originally or without query).
+	Credits         float64 // API credits consumed by the replay.
+	BudgetExhausted bool    // The replay stopped before the end because of the budget.
+}
+
+// Changed returns the entries whose replay failed or whose results differ from the recorded ones.
+func (rr ReplayReport) Changed() (changed []ReplayEntry) {
+	for _, entry := range rr.Entries {
+		if entry.Changed() {
+			changed = append(changed, entry)
+		}
+	}
+	return
+}
+
+// ReplayEntry is the replay of a single audit record.
+type ReplayEntry struct {
+	Record        AuditRecord
+	Err           error          // The error of the replayed call, if any.
+	SearchAnswer  *SearchAnswer  // The new answer of a replayed search.
+	ExtractAnswer *ExtractAnswer // The new answer of a replayed extract.
+	Search        *SearchDiff    // Differences with the recorded search answer, nil if it was not recorded.
+	Extract       *ExtractDiff   // Differences with the recorded extract answer, nil if it was not recorded.
+}
+
+// Changed returns true if the replay failed or its results differ from the recorded ones.
+func (re ReplayEntry) Changed() bool {
+	return re.Err != nil || (re.Search != nil && re.Search.Changed()) || (re.Extract != nil && re.Extract.Changed())
+}
+
+// SearchDiff represents the differences between a recorded search answer and a new one. URLs are compared by their canonical form.
+type SearchDiff struct {
+	AddedURLs     []string      // Results only present in the new answer.
+	RemovedURLs   []string      // Results only present in the recorded answer.
+	ScoreChanges  []ScoreChange // Results present in both answers with a different score.
+	OrderChanged  bool          // The common results are not in the same order.
+	AnswerChanged bool          // The generated answer differs.
+	BeforeAnswer  string
+	AfterAnswer   string
+}
+
+// Changed returns true if there is any difference.
+func (sd SearchDiff) Changed() bool {
+	return len(sd.AddedURLs) > 0 || len(sd.RemovedURLs) > 0 || len(sd.ScoreChanges) > 0 || sd.OrderChanged || sd.AnswerChanged
+}
+
+// ScoreChange represents the score change of a search result.
+type ScoreChange struct {
+	URL    string
+	Before float64
+	After  float64
+}
+
+// ExtractDiff represents the differences between a recorded extract answer and a new one. URLs are compared by their canonical form.
+type ExtractDiff struct {
+	AddedURLs      []string // URLs only successfully extracted in the new answer.
+	RemovedURLs    []string // URLs only successfully extracted in the recorded answer.
+	ContentChanged []string // URLs successfully extracted in both answers with a different content.
+}
+
+// Changed returns true if there is any difference.
+func (ed ExtractDiff) Changed() bool {
+	return len(ed.AddedURLs) > 0 || len(ed.RemovedURLs) > 0 || len(ed.ContentChanged) > 0
+}
+
+// Replay re-issues the calls of the audit records thru client, so the replayed calls use its credits and show in its
+// stats, and compares the new answers with the recorded ones when the audit log included them.
+// A records iteration error stops the replay and is returned alongside the partial report.
+func Replay(ctx context.Context, client Client, records iter.Seq2[AuditRecord, error], conf ReplayConfig) (report ReplayReport, err error) {
+	if conf.MaxCredits < 0 {
+		return report, errors.New("max credits must be non-negative")
+	}
+	for record, recordErr := range records {
+		if recordErr != nil {
+			return report, fmt.Errorf("failed to read audit record: %w", recordErr)
+		}
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		if (record.Error != "" && !conf.IncludeFailed) || (record.Search == nil && record.Extract == nil) {
+			report.Skipped++
+			continue
+		}
+		// Budget
+		if conf.MaxCredits > 0 && report.Credits+replayMaxCost(record) > conf.MaxCredits {
+			report.BudgetExhausted = true
+			return
+		}
+		// Replay
+		entry := ReplayEntry{
+			Record: record,
+		}
+		switch {
+		case record.Search != nil:
+			var answer SearchAnswer
+			if answer, entry.Err = client.Search(ctx, *record.Search); entry.Err == nil {
+				entry.SearchAnswer = &answer
+				report.Credits += searchUsage(*record.Search).TotalCost()
+				if record.SearchAnswer != nil {
+					diff := diffSearchAnswers(*record.SearchAnswer, answer, conf.ScoreTolerance)
+					entry.Search = &diff
+				}
+			}
+		case record.Extract != nil:
+			var answer ExtractAnswer
+			if answer, entry.Err = client.Extract(ctx, *record.Extract); entry.Err == nil {
+				entry.ExtractAnswer = &answer
+				report.Credits += extractUsage(*record.Extract, answer).TotalCost()
+				if record.ExtractAnswer != nil {
+					diff := diffExtractAnswers(*record.ExtractAnswer, answer)
+					entry.Extract = &diff
+				}
+			}
+		}
+		report.Entries = append(report.Entries, entry)
+		report.Replayed++
+	}
+	return
+}
+
+// replayMaxCost returns the worst case cost of replaying a record: every URL of an extract could succeed.
+func replayMaxCost(record AuditRecord) float64 {
+	switch {
+	case record.Search != nil:
+		return searchUsage(*record.Search).TotalCost()
+	case record.Extract != nil:
+		return extractUsage(*record.Extract, ExtractAnswer{
+			Results: make([]ExtractAnswerResult, len(record.Extract.URLs)),
+		}).TotalCost()
+	default:
+		return 0
+	}
+}
+
+func diffSearchAnswers(before, after SearchAnswer, scoreTolerance float64) (diff SearchDiff) {
+	beforeResults := indexSearchResults(before.Results)
+	afterResults := indexSearchResults(after.Results)
+	var beforeOrder, afterOrder []string
+	for _, result := range before.Results {
+		key := searchResultKey(result)
+		afterResult, found := afterResults[key]
+		if !found {
+			diff.RemovedURLs = append(diff.RemovedURLs, searchResultURL(result))
+			continue
+		}
+		beforeOrder = append(beforeOrder, key)
+		if math.Abs(afterResult.Score-result.Score) > scoreTolerance {
+			diff.ScoreChanges = append(diff.ScoreChanges, ScoreChange{
+				URL:    searchResultURL(result),
+				Before: result.Score,
+				After:  afterResult.Score,
+			})
+		}
+	}
+	for _, result := range after.Results {
+		key := searchResultKey(result)
+		if _, found := beforeResults[key]; !found {
+			diff.AddedURLs = append(diff.AddedURLs, searchResultURL(result))
+			continue
+		}
+		afterOrder = append(afterOrder, key)
+	}
+	diff.OrderChanged = !slices.Equal(beforeOrder, afterOrder)
+	if before.Answer != nil {
+		diff.BeforeAnswer = *before.Answer
+	}
+	if after.Answer != nil {
+		diff.AfterAnswer = *after.Answer
+	}
+	diff.AnswerChanged = diff.BeforeAnswer != diff.AfterAnswer
+	return
+}
+
+func indexSearchResults(results []SearchAnswerResult) map[string]SearchAnswerResult {
+	index := make(map[string]SearchAnswerResult, len(results))
+	for _, result := range results {
+		index[searchResultKey(result)] = result
+	}
+	return index
+}
+
+func searchResultURL(result SearchAnswerResult) string {
+	if result.URL == nil {
+		return ""
+	}
+	return result.URL.String()
+}
+
+func searchResultKey(result SearchAnswerResult) string {
+	return urlcanon.Key(searchResultURL(result))
+}
+
+func diffExtractAnswers(before, after ExtractAnswer) (diff ExtractDiff) {
+	beforeResults := indexExtractResults(before.Results)
+	afterResults := indexExtractResults(after.Results)
+	for _, result := range before.Results {
+		afterResult, found := afterResults[extractResultKey(result)]
+		switch {
+		case !found:
+			diff.RemovedURLs = append(diff.RemovedURLs, extractResultURL(result))
+		case afterResult.RawContent != result.RawContent:
+			diff.ContentChanged = append(diff.ContentChanged, extractResultURL(result))
+		}
+	}
+	for _, result := range after.Results {
+		if _, found := beforeResults[extractResultKey(result)]; !found {
+			diff.AddedURLs = append(diff.AddedURLs, extractResultURL(result))
+		}
+	}
+	return
+}
+
+func indexExtractResults(results []ExtractAnswerResult) map[string]ExtractAnswerResult {
+	index := make(map[string]ExtractAnswerResult, len(results))
+	for _, result := range results {
+		index[extractResultKey(result)] = result
+	}
+	return index
+}
+
+func extractResultURL(result ExtractAnswerResult) string {
+	if result.URL == nil {
+		return ""
+	}
+	return result.URL.String()
+}
+
+func extractResultKey(result ExtractAnswerResult) string {
+	return urlcanon.Key(extractResultURL(result))
+}
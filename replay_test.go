@@ -0,0 +1,109 @@
+package tavily
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReplayIntoSameAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit, err := NewAuditLog(AuditLogConfig{Path: path, MaxSize: 1, IncludeAnswers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer audit.Close()
+	api := &fakeAPI{}
+	root := newTestClient(t, api, ClientConfig{AuditLog: audit})
+	testSearch(t, root)
+	if _, err = root.Extract(context.Background(), ExtractRequest{URLs: []string{"https://example.com/a"}}); err != nil {
+		t.Fatal(err)
+	}
+	// the replayed calls are audited (and rotated) into the log being read: only the original records must be replayed
+	report, err := Replay(context.Background(), root, ReadAuditLogFiles(path), ReplayConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Replayed != 2 || len(api.calls()) != 4 {
+		t.Fatalf("expected the 2 original records to be replayed once, got %d replayed and %d calls", report.Replayed, len(api.calls()))
+	}
+	if changed := report.Changed(); len(changed) != 0 {
+		t.Errorf("expected identical answers, got %+v", changed)
+	}
+	if report.Credits != 1.2 {
+		t.Errorf("unexpected replay credits: %v", report.Credits)
+	}
+	if records := readTestAuditLog(t, path); len(records) != 4 {
+		t.Errorf("expected the replayed calls to be audited, got %d records", len(records))
+	}
+}
+
+func TestReplayDiff(t *testing.T) {
+	var searches atomic.Int32
+	api := &fakeAPI{handler: func(req *http.Request, payload map[string]any) *http.Response {
+		if req.URL.Path != "/"+EndpointSearch {
+			return defaultFakeHandler(req, payload)
+		}
+		if searches.Add(1) == 1 {
+			return jsonResponse(http.StatusOK, `{"query":"q","answer":"before","images":[],"results":[`+
+				`{"title":"a","url":"https://example.com/a","content":"c","score":0.9},`+
+				`{"title":"b","url":"https://example.com/b","content":"c","score":0.5}],"response_time":0.1}`)
+		}
+		return jsonResponse(http.StatusOK, `{"query":"q","answer":"after","images":[],"results":[`+
+			`{"title":"b","url":"https://EXAMPLE.com/b","content":"c","score":0.8},`+
+			`{"title":"c","url":"https://example.com/c","content":"c","score":0.1}],"response_time":0.1}`)
+	}}
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit, err := NewAuditLog(AuditLogConfig{Path: path, IncludeAnswers: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer audit.Close()
+	root := newTestClient(t, api, ClientConfig{AuditLog: audit})
+	testSearch(t, root)
+	report, err := Replay(context.Background(), root, ReadAuditLogFiles(path), ReplayConfig{ScoreTolerance: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Entries) != 1 || report.Entries[0].Search == nil {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	diff := *report.Entries[0].Search
+	if !slices.Equal(diff.RemovedURLs, []string{"https://example.com/a"}) || !slices.Equal(diff.AddedURLs, []string{"https://example.com/c"}) {
+		t.Errorf("unexpected results changes: %+v", diff)
+	}
+	if len(diff.ScoreChanges) != 1 || diff.ScoreChanges[0].Before != 0.5 || diff.ScoreChanges[0].After != 0.8 {
+		t.Errorf("expected the canonical URLs to be matched, got %+v", diff.ScoreChanges)
+	}
+	if !diff.AnswerChanged || diff.BeforeAnswer != "before" || diff.AfterAnswer != "after" || diff.OrderChanged {
+		t.Errorf("unexpected answer changes: %+v", diff)
+	}
+}
+
+func TestReplayBudgetAndSkipped(t *testing.T) {
+	query := SearchQuery{Query: "q", SearchDepth: SearchQueryDepthAdvanced}
+	records := []AuditRecord{
+		{Operation: EndpointSearch, Search: &query},
+		{Operation: EndpointSearch, Search: &query, Error: "failed"},
+		{Operation: EndpointSearch},
+		{Operation: EndpointSearch, Search: &query},
+	}
+	api := &fakeAPI{}
+	root := newTestClient(t, api, ClientConfig{})
+	report, err := Replay(context.Background(), root, func(yield func(AuditRecord, error) bool) {
+		for _, record := range records {
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}, ReplayConfig{MaxCredits: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Replayed != 1 || report.Skipped != 2 || !report.BudgetExhausted || report.Credits != 2 || len(api.calls()) != 1 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"path"
+	"time"
 )
 
 const (
@@ -16,6 +18,20 @@ const (
 	userAgent = "github.com/hekmon/tavily"
 )
 
+const (
+	// StatusPlanLimitExceeded is returned by the API when the API key or the plan credits limit has been reached.
+	StatusPlanLimitExceeded = 432
+	// StatusPayGoLimitExceeded is returned by the API when the pay as you go credits limit has been reached.
+	StatusPayGoLimitExceeded = 433
+)
+
+const (
+	// EndpointSearch is the name of the search endpoint, see https://docs.tavily.com/api-reference/endpoint/search
+	EndpointSearch = "search"
+	// EndpointExtract is the name of the extract endpoint, see https://docs.tavily.com/api-reference/endpoint/extract
+	EndpointExtract = "extract"
+)
+
 var (
 	baseURL *url.URL
 )
@@ -27,54 +43,134 @@ func init() {
 	}
 }
 
-func (c *mainClient) request(ctx context.Context, endpoint string, payload, response any) (err error) {
+// request executes the API call with a key from the pool. If the key is rejected by the API (authentication or quota error),
+// it is disabled for a while and the call is retried with the next available key, unless it was the last one: the API error
+// is then returned. The key used by the successful call is returned.
+func (c *mainClient) request(ctx context.Context, endpoint string, payload, response any) (key *poolKey, err error) {
 	// Prepare payload
 	var body bytes.Buffer
 	if payload != nil {
 		if err = json.NewEncoder(&body).Encode(payload); err != nil {
-			return fmt.Errorf("failed to marshal payload: %w", err)
+			return nil, fmt.Errorf("failed to marshal payload: %w", err)
+		}
+	}
+	// Report the waits of the whole call if requested
+	cl := callFromContext(ctx)
+	if meta := responseMetaFromContext(ctx); meta != nil && cl != nil {
+		defer func() {
+			meta.QueueWait = time.Duration(cl.queueWait.Load())
+			meta.RateLimitWait = time.Duration(cl.rateLimitWait.Load())
+		}()
+	}
+	// Respect the client concurrency limit
+	waited, err := c.concurrency.acquire(ctx)
+	cl.addQueueWait(waited)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for concurrency limiting: %w", err)
+	}
+	defer c.concurrency.release()
+	// Execute with the first key accepted by the API
+	var lastErr error
+	for {
+		if key, err = c.keys.acquire(); err != nil {
+			if lastErr != nil {
+				err = fmt.Errorf("%w: %w", err, lastErr)
+			}
+			return nil, err
 		}
+		err = c.requestWithKey(ctx, key, endpoint, body.Bytes(), payload != nil, response)
+		key.release()
+		var apiErr APIError
+		if errors.As(err, &apiErr) && apiErr.disablesKey() {
+			if !c.keys.disable(key, apiErr) {
+				// last available key: surface the API error
+				return
+			}
+			lastErr = err
+			continue
+		}
+		if err == nil {
+			// the key may have been tried again after its cooldown
+			key.enable()
+		}
+		return
 	}
+}
+
+// requestWithKey executes the API call with key and reports its outcome to the circuit breaker.
+func (c *mainClient) requestWithKey(ctx context.Context, key *poolKey, endpoint string, payload []byte, hasPayload bool, response any) (err error) {
 	// Create request
 	reqURL := *baseURL
 	reqURL.Path = path.Join(reqURL.Path, endpoint)
-	req, err := http.NewRequest("POST", reqURL.String(), &body)
+	req, err := http.NewRequestWithContext(ctx, "POST", reqURL.String(), bytes.NewReader(payload))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("User-Agent", userAgent)
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	if payload != nil {
+	req.Header.Set("Authorization", "Bearer "+key.value)
+	if hasPayload {
 		req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	}
 	if response != nil {
 		req.Header.Set("Accept", "application/json")
 	}
 	// Respect Tavily rate limits
-	if err = c.throughput.Wait(ctx); err != nil {
+	cl := callFromContext(ctx)
+	start := time.Now()
+	err = key.wait(ctx, endpoint)
+	cl.addRateLimitWait(time.Since(start))
+	if err != nil {
 		return fmt.Errorf("failed to wait for rate limiting: %w", err)
 	}
+	// Fail fast if the API is considered unavailable, once every wait is over so a half open probe is sent right away
+	probe, err := c.breaker.allow()
+	if err != nil {
+		return err
+	}
+	outcome := breakerIgnored
+	defer func() { c.breaker.record(probe, outcome) }()
 	// Execute request
+	meta := responseMetaFromContext(ctx)
+	if meta != nil {
+		meta.Attempts++
+	}
+	seq := c.debug.request(req, key, payload)
+	start = time.Now()
+	if meta != nil {
+		defer func() { meta.Latency = time.Since(start) }()
+	}
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		c.debug.response(seq, nil, nil, time.Since(start), err)
+		outcome = transportOutcome(err)
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
+	key.observe(resp)
+	cl.setStatus(resp.StatusCode)
+	meta.setResponse(key, resp)
+	outcome = statusOutcome(resp.StatusCode)
+	// Read response
+	body, err := io.ReadAll(resp.Body)
+	c.debug.response(seq, resp, body, time.Since(start), err)
+	if err != nil {
+		body = []byte(fmt.Sprintf("failed to read response body: %s", err))
+	}
 	// Handle status code
 	switch resp.StatusCode {
 	case http.StatusOK:
+		if err != nil {
+			return fmt.Errorf("failed to read response body: %w", err)
+		}
 		if response == nil {
 			// no need to continue to unmarshalling
-			return nil
+			return
 		}
 	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound,
 		http.StatusMethodNotAllowed, http.StatusUnprocessableEntity, http.StatusTooManyRequests,
+		StatusPlanLimitExceeded, StatusPayGoLimitExceeded,
 		http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
 		// Handle known errors
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			body = []byte(fmt.Sprintf("failed to read response body: %s", err))
-		}
 		return APIError{
 			Code: resp.StatusCode,
 			Body: body,
@@ -83,12 +179,40 @@ func (c *mainClient) request(ctx context.Context, endpoint string, payload, resp
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 	// Unmarshal response
-	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return fmt.Errorf("failed to unmarshal response: %w", err)
+	var schemaErr *SchemaError
+	if err = json.Unmarshal(body, response); err != nil {
+		if !errors.As(err, &schemaErr) || c.onSchemaDrift == nil {
+			return DecodeError{
+				Err:  err,
+				Body: body,
+			}
+		}
+		err = nil
+	}
+	if c.onSchemaDrift != nil {
+		c.reportSchemaDrifts(endpoint, response, schemaErr)
+	}
+	if keeper, ok := response.(rawKeeper); ok && c.keepRaw {
+		keeper.setRaw(body)
 	}
 	return
 }
 
+// reportSchemaDrifts reports the unknown fields of response and the type mismatches of schemaErr (if any) to the schema drift callback.
+func (c *mainClient) reportSchemaDrifts(endpoint string, response any, schemaErr *SchemaError) {
+	var drifts []SchemaDrift
+	if reporter, ok := response.(unknownFieldsReporter); ok {
+		drifts = reporter.unknownFields()
+	}
+	if schemaErr != nil {
+		drifts = append(drifts, schemaErr.Drifts...)
+	}
+	for _, drift := range drifts {
+		drift.Endpoint = endpoint
+		c.onSchemaDrift(drift)
+	}
+}
+
 // APIError represents a known error from the Tavily API.
 // https://docs.tavily.com/docs/rest-api/api-reference#error-codes
 type APIError struct {
@@ -97,5 +221,26 @@ type APIError struct {
 }
 
 func (e APIError) Error() string {
-	return fmt.Sprintf("Tavily API error: %d %s", e.Code, http.StatusText(e.Code))
+	return fmt.Sprintf("Tavily API error: %d %s", e.Code, statusText(e.Code))
+}
+
+// disablesKey returns true if the error means the API key used can not be used anymore (invalid key or exhausted quota).
+func (e APIError) disablesKey() bool {
+	switch e.Code {
+	case http.StatusUnauthorized, StatusPlanLimitExceeded, StatusPayGoLimitExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+func statusText(code int) string {
+	switch code {
+	case StatusPlanLimitExceeded:
+		return "Plan Limit Exceeded"
+	case StatusPayGoLimitExceeded:
+		return "Pay As You Go Limit Exceeded"
+	default:
+		return http.StatusText(code)
+	}
 }
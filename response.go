@@ -0,0 +1,60 @@
+package tavily
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ResponseMeta holds the HTTP metadata of an API call. Pass it thru the context with WithResponseMeta to have it
+// filled by the client once the call is done.
+type ResponseMeta struct {
+	RequestID     string        // The request ID of the API (from the response body or the X-Request-Id header), to cite in support tickets.
+	StatusCode    int           // HTTP status code of the last API response.
+	Header        http.Header   // Headers of the last API response.
+	Key           string        // Redacted version of the API key used by the last attempt.
+	Attempts      int           // Number of HTTP requests sent (more than one when a key was rejected by the API and another one was tried).
+	Latency       time.Duration // Client side latency of the last HTTP request, from sending it to reading its response body.
+	RateLimitWait time.Duration // Time spent waiting for the rate limiters (sessions, keys and endpoints).
+	QueueWait     time.Duration // Time spent waiting for the concurrency limiters (sessions and client).
+}
+
+type responseMetaCtxKey struct{}
+
+// WithResponseMeta returns a copy of ctx capturing the HTTP metadata of the call made with it into meta.
+// meta must not be read before the call returns and a context must not be shared by concurrent calls: ExtractIter, which
+// shares its context between its batches, ignores it and the asynchronous calls (SearchAsync, ExtractAsync) fill it
+// when their result is read with Future.Wait.
+func WithResponseMeta(ctx context.Context, meta *ResponseMeta) context.Context {
+	return context.WithValue(ctx, responseMetaCtxKey{}, meta)
+}
+
+// withoutResponseMeta returns a copy of ctx not capturing the HTTP metadata of the calls made with it.
+func withoutResponseMeta(ctx context.Context) context.Context {
+	if responseMetaFromContext(ctx) == nil {
+		return ctx
+	}
+	return WithResponseMeta(ctx, nil)
+}
+
+// responseMetaFromContext returns the response metadata capture carried by ctx, nil if none.
+func responseMetaFromContext(ctx context.Context) *ResponseMeta {
+	meta, _ := ctx.Value(responseMetaCtxKey{}).(*ResponseMeta)
+	return meta
+}
+
+func (rm *ResponseMeta) setResponse(key *poolKey, resp *http.Response) {
+	if rm == nil {
+		return
+	}
+	rm.StatusCode = resp.StatusCode
+	rm.Header = resp.Header
+	rm.Key = redactKey(key.value)
+	rm.RequestID = resp.Header.Get("X-Request-Id")
+}
+
+func (rm *ResponseMeta) setRequestID(requestID string) {
+	if rm != nil && requestID != "" {
+		rm.RequestID = requestID
+	}
+}
@@ -0,0 +1,72 @@
+package tavily
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestResponseMeta(t *testing.T) {
+	rejected := &atomic.Bool{}
+	rejected.Store(true)
+	api := rejectingKeysAPI(map[string]*atomic.Bool{"tvly-dev-testkey0001": rejected})
+	inner := api.handler
+	api.handler = func(req *http.Request, payload map[string]any) *http.Response {
+		resp := inner(req, payload)
+		resp.Header.Set("X-Request-Id", "req-123")
+		return resp
+	}
+	root := newTestClient(t, api, ClientConfig{
+		APIKeys:     []APIKey{testKey("tvly-dev-testkey0001"), testKey("tvly-dev-testkey0002")},
+		KeyStrategy: KeyStrategyFailover,
+	})
+	var meta ResponseMeta
+	if _, err := root.Search(WithResponseMeta(context.Background(), &meta), SearchQuery{Query: "q"}); err != nil {
+		t.Fatal(err)
+	}
+	if meta.Attempts != 2 || meta.StatusCode != http.StatusOK || meta.Key != redactKey("tvly-dev-testkey0002") {
+		t.Errorf("expected the metadata of the retried request, got %+v", meta)
+	}
+	if meta.RequestID != "req-123" || meta.Header.Get("Content-Type") != "application/json" || meta.Latency <= 0 {
+		t.Errorf("unexpected response metadata: %+v", meta)
+	}
+}
+
+func TestResponseMetaConcurrentCalls(t *testing.T) {
+	root := newTestClient(t, &fakeAPI{}, ClientConfig{})
+	var meta ResponseMeta
+	ctx := WithResponseMeta(context.Background(), &meta)
+	// concurrent calls sharing ctx must not race on meta (see go test -race)
+	request := ExtractRequest{URLs: []string{"https://example.com/1", "https://example.com/2", "https://example.com/3"}}
+	for _, err := range ExtractIter(ctx, root, request, ExtractIterConfig{BatchSize: 1, Parallelism: 3}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if meta.Attempts != 0 {
+		t.Errorf("expected the metadata to be left untouched by the batches, got %+v", meta)
+	}
+	// the futures fill it when waited
+	futures := []*Future[SearchAnswer]{SearchAsync(ctx, root, SearchQuery{Query: "q"}), SearchAsync(ctx, root, SearchQuery{Query: "q"})}
+	for _, future := range futures {
+		<-future.Done()
+	}
+	if meta.Attempts != 0 {
+		t.Errorf("expected the metadata to be filled by Wait only, got %+v", meta)
+	}
+	if _, err := futures[0].Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if meta.Attempts != 1 || meta.StatusCode != http.StatusOK || meta.Latency <= 0 {
+		t.Errorf("expected the future call metadata, got %+v", meta)
+	}
+	// still captured by a direct call
+	meta = ResponseMeta{}
+	if _, err := root.Search(ctx, SearchQuery{Query: "q"}); err != nil {
+		t.Fatal(err)
+	}
+	if meta.Attempts != 1 {
+		t.Errorf("expected the direct call metadata, got %+v", meta)
+	}
+}
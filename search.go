@@ -3,10 +3,11 @@ package tavily
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/url"
 	"time"
+
+	"github.com/hekmon/tavily/v2/urlcanon"
 )
 
 const (
@@ -17,72 +18,83 @@ const (
 // SearchQuery represents the parameters for a search query.
 type SearchQuery struct {
 	Query                    string                   `json:"query"`                                // The search query you want to execute with Tavily.
-	Topic                    SearchQueryTopic         `json:"topic,omitempty"`                      // The category of the search. This will determine which of our agents will be used for the search. Currently: only "general" and "news" are supported. Default is "general".
-	SearchDepth              SearchQueryDepth         `json:"search_depth,omitempty"`               // The depth of the search. It can be "basic" or "advanced". Default is "basic" unless specified otherwise in a given method.
+	Topic                    SearchQueryTopic         `json:"topic,omitempty"`                      // The category of the search. This will determine which of our agents will be used for the search. Default is "general".
+	SearchDepth              SearchQueryDepth         `json:"search_depth,omitempty"`               // The depth of the search. Default is "basic".
 	MaxResults               int                      `json:"max_results,omitempty"`                // The maximum number of search results to return. Default is 5.
-	TimeRange                SearchQueryTimeRange     `json:"time_range,omitempty"`                 // The time range back from the current date to filter results.
-	Days                     int                      `json:"days,omitempty"`                       // The number of days back from the current date to include in the search results. This specifies the time frame of data to be retrieved. Please note that this feature is only available when using the "news" search topic. Default is 3.
-	IncludeAnswer            SearchQueryIncludeAnswer `json:"include_answer,omitempty"`             // Include a short answer to original query. Default is False.
-	IncludeRawContent        bool                     `json:"include_raw_content"`                  // Include the cleaned and parsed HTML content of each search result. Default is False.
-	IncludeImages            bool                     `json:"include_images,omitempty"`             // Include a list of query-related images in the response. Default is False.
-	IncludeImageDescriptions bool                     `json:"include_image_descriptions,omitempty"` // When include_images is set to True, this option adds descriptive text for each image. Default is False.
-	IncludeDomains           []string                 `json:"include_domains,omitempty"`            // A list of domains to specifically include in the search results. Default is [], which includes all domains.
-	ExcludeDomains           []string                 `json:"exclude_domains,omitempty"`            // A list of domains to specifically exclude from the search results. Default is [], which doesn't exclude any domains.
+	TimeRange                SearchQueryTimeRange     `json:"time_range,omitempty"`                 // The time range back from the current date to filter results. Only available with the "general" topic.
+	Days                     int                      `json:"days,omitempty"`                       // The number of days back from the current date to include in the search results. Only available with the "news" topic. Default is 3.
+	IncludeAnswer            SearchQueryIncludeAnswer `json:"include_answer,omitempty"`             // Include a short (basic) or detailed (advanced) answer to original query. Default is no answer.
+	IncludeRawContent        bool                     `json:"include_raw_content"`                  // Include the cleaned and parsed HTML content of each search result. Default is false.
+	IncludeImages            bool                     `json:"include_images,omitempty"`             // Include a list of query-related images in the response. Default is false.
+	IncludeImageDescriptions bool                     `json:"include_image_descriptions,omitempty"` // When include_images is true, this option adds descriptive text for each image. Default is false.
+	IncludeDomains           []string                 `json:"include_domains,omitempty"`            // A list of domains to specifically include in the search results. Default is empty, which includes all domains.
+	ExcludeDomains           []string                 `json:"exclude_domains,omitempty"`            // A list of domains to specifically exclude from the search results. Default is empty, which doesn't exclude any domains.
 }
 
-func (sq SearchQuery) Validate() error {
+// Validate checks the query and returns a ValidationError listing every invalid field.
+func (sq SearchQuery) Validate() (err error) {
+	_, err = sq.validate()
+	return
+}
+
+// validate checks the query and returns a copy of it with its domains normalized (see NormalizeDomains).
+func (sq SearchQuery) validate() (normalized SearchQuery, err error) {
+	var verr ValidationError
 	// Query
 	if sq.Query == "" {
-		return errors.New("query is required")
+		verr.add("query", sq.Query, "is required")
 	}
 	// Topic
 	switch sq.Topic {
 	case SearchQueryTopicGeneral, SearchQueryTopicNews, "":
 	default:
-		return errors.New("invalid topic")
+		verr.add("topic", sq.Topic, enumRule(SearchQueryTopicGeneral, SearchQueryTopicNews))
 	}
 	// Search Depth
 	switch sq.SearchDepth {
 	case SearchQueryDepthBasic, SearchQueryDepthAdvanced, "":
 	default:
-		return errors.New("invalid search depth")
+		verr.add("search_depth", sq.SearchDepth, enumRule(SearchQueryDepthBasic, SearchQueryDepthAdvanced))
 	}
 	// Max Results
 	switch {
 	case sq.MaxResults < 0:
-		return errors.New("max_results must be a non-negative integer")
+		verr.add("max_results", sq.MaxResults, "must be a non-negative integer")
 	case sq.MaxResults > SearchMaxPossibleResults:
-		return fmt.Errorf("max_results must be less than or equal to %d", SearchMaxPossibleResults)
+		verr.add("max_results", sq.MaxResults, fmt.Sprintf("must be less than or equal to %d", SearchMaxPossibleResults))
 	}
 	// Time Range
 	if sq.TimeRange != SearchQueryTimeRangeDisabled {
-		if sq.Topic == SearchQueryTopicNews {
-			return fmt.Errorf("time_range can only be specified when using the %q topic", SearchQueryTopicGeneral)
-		}
 		switch sq.TimeRange {
 		case SearchQueryTimeRangeDay, SearchQueryTimeRangeWeek, SearchQueryTimeRangeMonth, SearchQueryTimeRangeYear:
+			if sq.Topic == SearchQueryTopicNews {
+				verr.add("time_range", sq.TimeRange, fmt.Sprintf("can only be specified when using the %q topic", SearchQueryTopicGeneral))
+			}
 		default:
-			return errors.New("invalid time range")
+			verr.add("time_range", sq.TimeRange, enumRule(SearchQueryTimeRangeDay, SearchQueryTimeRangeWeek, SearchQueryTimeRangeMonth, SearchQueryTimeRangeYear))
 		}
 	}
 	// Days
 	switch {
 	case sq.Days < 0:
-		return errors.New("days must be a non-negative integer")
+		verr.add("days", sq.Days, "must be a non-negative integer")
 	case sq.Days > 0 && sq.Topic != SearchQueryTopicNews:
-		return fmt.Errorf("days can only be specified when using the %q topic", SearchQueryTopicNews)
+		verr.add("days", sq.Days, fmt.Sprintf("can only be specified when using the %q topic", SearchQueryTopicNews))
 	}
 	// Include Answer
 	switch sq.IncludeAnswer {
 	case SearchQueryIncludeAnswerNone, SearchQueryIncludeAnswerBasic, SearchQueryIncludeAnswerAdvanced:
 	default:
-		return errors.New("invalid include_answer value")
+		verr.add("include_answer", sq.IncludeAnswer, enumRule(SearchQueryIncludeAnswerBasic, SearchQueryIncludeAnswerAdvanced))
 	}
 	// Images descriptions
 	if !sq.IncludeImages && sq.IncludeImageDescriptions {
-		return errors.New("include_image_descriptions can only be true when include_images is true")
+		verr.add("include_image_descriptions", sq.IncludeImageDescriptions, "can only be true when include_images is true")
 	}
-	return nil
+	// Domains
+	normalized = sq
+	normalized.IncludeDomains, normalized.ExcludeDomains = sq.validateDomains(&verr)
+	return normalized, verr.err()
 }
 
 type SearchQueryDepth string
@@ -121,40 +133,73 @@ const (
 // See https://docs.tavily.com/api-reference/endpoint/search for more information.
 func (c *mainClient) Search(ctx context.Context, query SearchQuery) (answer SearchAnswer, err error) {
 	// Prepare query
-	if err = query.Validate(); err != nil {
+	if query, err = query.validate(); err != nil {
 		err = fmt.Errorf("failed to validate search query: %w", err)
 		return
 	}
 	// Execute
-	if err = c.request(ctx, "search", query, &answer); err != nil {
+	key, err := c.request(ctx, EndpointSearch, query, &answer)
+	if err != nil {
 		err = fmt.Errorf("failed to execute API query: %w", err)
+		return
 	}
+	responseMetaFromContext(ctx).setRequestID(answer.RequestID)
+	// Credits accounting
+	key.addCredits(searchUsage(query).TotalCost())
 	return
 }
 
 // SearchAnswer represents the response from the search API.
 // https://docs.tavily.com/docs/rest-api/api-reference#response
 type SearchAnswer struct {
-	Query        string               `json:"query"`
-	Answer       *string              `json:"answer"`
-	Images       []SearchAnswerImage  `json:"images"`
-	Results      []SearchAnswerResult `json:"results"`
-	ResponseTime time.Duration        `json:"-"`
+	Query        string                     `json:"query"`
+	Answer       *string                    `json:"answer"`
+	Images       []SearchAnswerImage        `json:"images"`
+	Results      []SearchAnswerResult       `json:"results"`
+	ResponseTime time.Duration              `json:"-"`
+	RequestID    string                     `json:"request_id,omitempty"`
+	Raw          json.RawMessage            `json:"-"` // The raw JSON response, only set if the client keeps raw responses.
+	Extra        map[string]json.RawMessage `json:"-"` // Fields of the response not known by this package.
 }
 
-func (sa *SearchAnswer) UnmarshalJSON(data []byte) (err error) {
-	type mask SearchAnswer
-	tmp := struct {
-		*mask
-		ResponseTime float64 `json:"response_time"`
-	}{
-		mask: (*mask)(sa),
+// Deduplicate returns a copy of the answer without the results sharing the same canonical URL (see the urlcanon package)
+// as a previous one, the best ranked being kept. Results without URL are kept. The client returns the results as sent
+// by the API: use it to drop the ones returned twice under different forms or to merge several answers. The Raw and
+// Extra fields of the answer are copied as is and still describe the original results.
+func (sa SearchAnswer) Deduplicate() SearchAnswer {
+	seen := make(map[string]struct{}, len(sa.Results))
+	results := make([]SearchAnswerResult, 0, len(sa.Results))
+	for _, result := range sa.Results {
+		if result.URL != nil {
+			key := urlcanon.Key(result.URL.String())
+			if _, found := seen[key]; found {
+				continue
+			}
+			seen[key] = struct{}{}
+		}
+		results = append(results, result)
 	}
-	if err = json.Unmarshal(data, &tmp); err != nil {
-		return fmt.Errorf("failed to unmarshal JSON into tmp struct: %w", err)
+	sa.Results = results
+	return sa
+}
+
+func (sa *SearchAnswer) UnmarshalJSON(data []byte) (err error) {
+	var (
+		od           objectDecoder
+		responseTime float64
+	)
+	if sa.Extra, err = od.object(data, map[string]any{
+		"query":         &sa.Query,
+		"answer":        &sa.Answer,
+		"images":        sliceField(&sa.Images),
+		"results":       sliceField(&sa.Results),
+		"response_time": &responseTime,
+		"request_id":    &sa.RequestID,
+	}); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON object: %w", err)
 	}
-	sa.ResponseTime = time.Duration(tmp.ResponseTime * float64(time.Second))
-	return
+	sa.ResponseTime = time.Duration(responseTime * float64(time.Second))
+	return od.err()
 }
 
 func (sa SearchAnswer) MarshalJSON() ([]byte, error) {
@@ -166,29 +211,45 @@ func (sa SearchAnswer) MarshalJSON() ([]byte, error) {
 		mask:         mask(sa),
 		ResponseTime: sa.ResponseTime.Seconds(),
 	}
-	return json.Marshal(tmp)
+	return marshalWithExtra(tmp, sa.Extra)
+}
+
+func (sa *SearchAnswer) setRaw(json []byte) {
+	sa.Raw = json
+}
+
+func (sa *SearchAnswer) unknownFields() (drifts []SchemaDrift) {
+	drifts = extraDrifts("", sa.Extra)
+	for index, image := range sa.Images {
+		drifts = append(drifts, extraDrifts(fmt.Sprintf("images[%d]", index), image.Extra)...)
+	}
+	for index, result := range sa.Results {
+		drifts = append(drifts, extraDrifts(fmt.Sprintf("results[%d]", index), result.Extra)...)
+	}
+	return
 }
 
 type SearchAnswerImage struct {
-	URL         *url.URL `json:"-"`
-	Description string   `json:"description"`
+	URL         *url.URL                   `json:"-"`
+	Description string                     `json:"description"`
+	Extra       map[string]json.RawMessage `json:"-"` // Fields of the response not known by this package.
 }
 
 func (sai *SearchAnswerImage) UnmarshalJSON(data []byte) (err error) {
-	type mask SearchAnswerImage
-	tmp := struct {
-		URL string `json:"url"`
-		*mask
-	}{
-		mask: (*mask)(sai),
+	var (
+		od     objectDecoder
+		rawURL string
+	)
+	if sai.Extra, err = od.object(data, map[string]any{
+		"url":         &rawURL,
+		"description": &sai.Description,
+	}); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON object: %w", err)
 	}
-	if err = json.Unmarshal(data, &tmp); err != nil {
-		return fmt.Errorf("failed to unmarshal JSON into tmp struct: %w", err)
-	}
-	if sai.URL, err = url.Parse(tmp.URL); err != nil {
+	if sai.URL, err = url.Parse(rawURL); err != nil {
 		return fmt.Errorf("failed to parse URL: %w", err)
 	}
-	return
+	return od.err()
 }
 
 func (sai SearchAnswerImage) MarshalJSON() ([]byte, error) {
@@ -200,32 +261,36 @@ func (sai SearchAnswerImage) MarshalJSON() ([]byte, error) {
 		URL:  sai.URL.String(),
 		mask: mask(sai),
 	}
-	return json.Marshal(tmp)
+	return marshalWithExtra(tmp, sai.Extra)
 }
 
 type SearchAnswerResult struct {
-	Title      string   `json:"title"`
-	URL        *url.URL `json:"-"`
-	Content    string   `json:"content"`
-	Score      float64  `json:"score"`
-	RawContent *string  `json:"raw_content"`
+	Title      string                     `json:"title"`
+	URL        *url.URL                   `json:"-"`
+	Content    string                     `json:"content"`
+	Score      float64                    `json:"score"`
+	RawContent *string                    `json:"raw_content"`
+	Extra      map[string]json.RawMessage `json:"-"` // Fields of the response not known by this package.
 }
 
 func (sar *SearchAnswerResult) UnmarshalJSON(data []byte) (err error) {
-	type mask SearchAnswerResult
-	tmp := struct {
-		*mask
-		URL string `json:"url"`
-	}{
-		mask: (*mask)(sar),
+	var (
+		od     objectDecoder
+		rawURL string
+	)
+	if sar.Extra, err = od.object(data, map[string]any{
+		"title":       &sar.Title,
+		"url":         &rawURL,
+		"content":     &sar.Content,
+		"score":       &sar.Score,
+		"raw_content": &sar.RawContent,
+	}); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON object: %w", err)
 	}
-	if err = json.Unmarshal(data, &tmp); err != nil {
-		return fmt.Errorf("failed to unmarshal JSON into tmp struct: %w", err)
-	}
-	if sar.URL, err = url.Parse(tmp.URL); err != nil {
+	if sar.URL, err = url.Parse(rawURL); err != nil {
 		return fmt.Errorf("failed to parse URL: %w", err)
 	}
-	return
+	return od.err()
 }
 
 func (sar SearchAnswerResult) MarshalJSON() ([]byte, error) {
@@ -237,5 +302,5 @@ func (sar SearchAnswerResult) MarshalJSON() ([]byte, error) {
 		URL:  sar.URL.String(),
 		mask: mask(sar),
 	}
-	return json.Marshal(tmp)
+	return marshalWithExtra(tmp, sar.Extra)
 }
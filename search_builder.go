@@ -0,0 +1,209 @@
+package tavily
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// SearchQueryBuilder builds search queries: each method adjusts the settings it conflicts with (eg News clears the time
+// range which is not supported by the news topic). Builders are values, each method returns a modified copy, allowing
+// to reuse a partially configured builder.
+type SearchQueryBuilder struct {
+	query SearchQuery
+}
+
+// NewSearchQuery returns a builder of a search query with the default settings.
+func NewSearchQuery(query string) SearchQueryBuilder {
+	return SearchQueryBuilder{
+		query: SearchQuery{
+			Query: query,
+		},
+	}
+}
+
+// Query sets the search query.
+func (b SearchQueryBuilder) Query(query string) SearchQueryBuilder {
+	b.query.Query = query
+	return b
+}
+
+// Preset replaces every setting but the search query by the ones of preset, as is: they are validated by Build.
+func (b SearchQueryBuilder) Preset(preset SearchPreset) SearchQueryBuilder {
+	b.query = preset.searchQuery(b.query.Query)
+	return b
+}
+
+// General uses the general topic. Days are converted to the smallest time range covering them.
+func (b SearchQueryBuilder) General() SearchQueryBuilder {
+	if b.query.Topic == SearchQueryTopicNews && b.query.Days > 0 {
+		b.query.TimeRange = daysToTimeRange(b.query.Days)
+	}
+	b.query.Topic = SearchQueryTopicGeneral
+	b.query.Days = 0
+	return b
+}
+
+// News uses the news topic, limited to the last days (0 for the API default). The time range, if any, is cleared.
+func (b SearchQueryBuilder) News(days int) SearchQueryBuilder {
+	b.query.Topic = SearchQueryTopicNews
+	b.query.Days = max(days, 0)
+	b.query.TimeRange = SearchQueryTimeRangeDisabled
+	return b
+}
+
+// Basic uses the basic search depth.
+func (b SearchQueryBuilder) Basic() SearchQueryBuilder {
+	b.query.SearchDepth = SearchQueryDepthBasic
+	return b
+}
+
+// Advanced uses the advanced search depth.
+func (b SearchQueryBuilder) Advanced() SearchQueryBuilder {
+	b.query.SearchDepth = SearchQueryDepthAdvanced
+	return b
+}
+
+// MaxResults sets the maximum number of results, bounded to [0, SearchMaxPossibleResults] (0 for the API default).
+func (b SearchQueryBuilder) MaxResults(maxResults int) SearchQueryBuilder {
+	b.query.MaxResults = min(max(maxResults, 0), SearchMaxPossibleResults)
+	return b
+}
+
+// Within limits the results to a time range. With the news topic, it is converted to the equivalent number of days.
+func (b SearchQueryBuilder) Within(timeRange SearchQueryTimeRange) SearchQueryBuilder {
+	if b.query.Topic == SearchQueryTopicNews {
+		b.query.Days = timeRangeToDays(timeRange)
+		return b
+	}
+	b.query.TimeRange = timeRange
+	return b
+}
+
+// Since limits the results to the ones published since t. With the news topic, it is converted to a number of days,
+// otherwise to the smallest time range covering it (no time range if t is more than a year ago).
+func (b SearchQueryBuilder) Since(t time.Time) SearchQueryBuilder {
+	days := max(int(math.Ceil(time.Since(t).Hours()/24)), 1)
+	if b.query.Topic == SearchQueryTopicNews {
+		b.query.Days = days
+		return b
+	}
+	b.query.TimeRange = daysToTimeRange(days)
+	return b
+}
+
+// WithAnswer includes a short answer generated from the results.
+func (b SearchQueryBuilder) WithAnswer() SearchQueryBuilder {
+	b.query.IncludeAnswer = SearchQueryIncludeAnswerBasic
+	return b
+}
+
+// WithAdvancedAnswer includes a detailed answer generated from the results.
+func (b SearchQueryBuilder) WithAdvancedAnswer() SearchQueryBuilder {
+	b.query.IncludeAnswer = SearchQueryIncludeAnswerAdvanced
+	return b
+}
+
+// WithRawContent includes the cleaned and parsed HTML content of each result.
+func (b SearchQueryBuilder) WithRawContent() SearchQueryBuilder {
+	b.query.IncludeRawContent = true
+	return b
+}
+
+// WithImages includes query related images, with their descriptions if requested.
+func (b SearchQueryBuilder) WithImages(descriptions bool) SearchQueryBuilder {
+	b.query.IncludeImages = true
+	b.query.IncludeImageDescriptions = descriptions
+	return b
+}
+
+// OnlyDomains restricts the results to domains (added to the ones already included). They are removed from the excluded ones.
+func (b SearchQueryBuilder) OnlyDomains(domains ...string) SearchQueryBuilder {
+	b.query.IncludeDomains = appendDomains(b.query.IncludeDomains, domains)
+	b.query.ExcludeDomains = removeDomains(b.query.ExcludeDomains, domains)
+	return b
+}
+
+// ExcludeDomains excludes domains from the results (added to the ones already excluded). They are removed from the included ones.
+func (b SearchQueryBuilder) ExcludeDomains(domains ...string) SearchQueryBuilder {
+	b.query.ExcludeDomains = appendDomains(b.query.ExcludeDomains, domains)
+	b.query.IncludeDomains = removeDomains(b.query.IncludeDomains, domains)
+	return b
+}
+
+// Build validates and returns the search query. The methods of the builder can not prevent every invalid query
+// (eg an empty search query, an invalid domain or a preset with conflicting settings).
+func (b SearchQueryBuilder) Build() (query SearchQuery, err error) {
+	if err = b.query.Validate(); err != nil {
+		return SearchQuery{}, fmt.Errorf("invalid search query: %w", err)
+	}
+	query = b.query
+	query.IncludeDomains = slices.Clone(query.IncludeDomains)
+	query.ExcludeDomains = slices.Clone(query.ExcludeDomains)
+	return
+}
+
+// appendDomains returns a new list of list and domains, without duplicates (compared by their canonical form).
+func appendDomains(list, domains []string) (result []string) {
+	result = slices.Clone(list)
+	for _, domain := range domains {
+		if !containsDomain(result, domain) {
+			result = append(result, domain)
+		}
+	}
+	return
+}
+
+// removeDomains returns a new list of list without domains (compared by their canonical form), nil if empty.
+func removeDomains(list, domains []string) (result []string) {
+	for _, domain := range list {
+		if !containsDomain(domains, domain) {
+			result = append(result, domain)
+		}
+	}
+	return
+}
+
+func containsDomain(list []string, domain string) bool {
+	key := domainKey(domain)
+	return slices.ContainsFunc(list, func(candidate string) bool { return domainKey(candidate) == key })
+}
+
+// domainKey returns the canonical form of domain, or domain itself if it is invalid.
+func domainKey(domain string) string {
+	if normalized, err := NormalizeDomain(domain); err == nil {
+		return normalized
+	}
+	return domain
+}
+
+func daysToTimeRange(days int) SearchQueryTimeRange {
+	switch {
+	case days <= 1:
+		return SearchQueryTimeRangeDay
+	case days <= 7:
+		return SearchQueryTimeRangeWeek
+	case days <= 31:
+		return SearchQueryTimeRangeMonth
+	case days <= 366:
+		return SearchQueryTimeRangeYear
+	default:
+		return SearchQueryTimeRangeDisabled
+	}
+}
+
+func timeRangeToDays(timeRange SearchQueryTimeRange) int {
+	switch timeRange {
+	case SearchQueryTimeRangeDay:
+		return 1
+	case SearchQueryTimeRangeWeek:
+		return 7
+	case SearchQueryTimeRangeMonth:
+		return 31
+	case SearchQueryTimeRangeYear:
+		return 366
+	default:
+		return 0
+	}
+}
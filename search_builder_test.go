@@ -0,0 +1,74 @@
+package tavily
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSearchQueryBuilder(t *testing.T) {
+	base := NewSearchQuery("q").Advanced().Within(SearchQueryTimeRangeWeek).OnlyDomains("example.com", "other.com")
+	query, err := base.News(0).ExcludeDomains("OTHER.com").WithAnswer().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Topic != SearchQueryTopicNews || query.TimeRange != SearchQueryTimeRangeDisabled || query.SearchDepth != SearchQueryDepthAdvanced {
+		t.Errorf("expected the news topic to clear the time range: %+v", query)
+	}
+	if !slices.Equal(query.IncludeDomains, []string{"example.com"}) || !slices.Equal(query.ExcludeDomains, []string{"OTHER.com"}) {
+		t.Errorf("expected the excluded domain to be removed from the included ones: %+v", query)
+	}
+	// the base builder is not modified
+	if query, err = base.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if query.Topic != "" || query.TimeRange != SearchQueryTimeRangeWeek || len(query.IncludeDomains) != 2 || query.IncludeAnswer != "" {
+		t.Errorf("unexpected base query: %+v", query)
+	}
+	// days and time ranges are converted when switching topics
+	if query, err = NewSearchQuery("q").News(0).Since(time.Now().Add(-50 * time.Hour)).General().Build(); err != nil {
+		t.Fatal(err)
+	}
+	if query.Days != 0 || query.TimeRange != SearchQueryTimeRangeWeek {
+		t.Errorf("expected 3 days to become a week time range: %+v", query)
+	}
+}
+
+func TestSearchQueryBuilderValidation(t *testing.T) {
+	var verr ValidationError
+	if _, err := NewSearchQuery("").Build(); !errors.As(err, &verr) || verr.Fields[0].Field != "query" {
+		t.Errorf("expected the empty query to be rejected, got %v", err)
+	}
+	// presets are applied as is
+	preset := SearchPreset{Days: 3}
+	if _, err := preset.Query("q").Build(); !errors.As(err, &verr) || verr.Fields[0].Field != "days" {
+		t.Errorf("expected the preset days without the news topic to be rejected, got %v", err)
+	}
+	query, err := DefaultSearchPresets.Query("news", "q")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if built, err := query.Build(); err != nil || built.Days != 3 || built.Query != "q" {
+		t.Errorf("unexpected news preset query: %+v (%v)", built, err)
+	}
+}
+
+func TestParseSearchPresets(t *testing.T) {
+	presets, err := ParseSearchPresets([]byte(`{"quick": {"max_results": 3, "include_answer": "basic"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if presets["quick"].MaxResults != 3 || presets["quick"].IncludeAnswer != SearchQueryIncludeAnswerBasic {
+		t.Errorf("unexpected presets: %+v", presets)
+	}
+	if _, err = ParseSearchPresets([]byte(`{"quick": {"max_result": 3}}`)); err == nil {
+		t.Error("expected the unknown setting to be rejected")
+	}
+	if _, err = ParseSearchPresets([]byte(`{"quick": {"days": 3}}`)); err == nil {
+		t.Error("expected the invalid preset to be rejected")
+	}
+	if err = DefaultSearchPresets.Validate(); err != nil {
+		t.Errorf("invalid default presets: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package tavily
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// SearchPreset is a named reusable set of search settings (every SearchQuery setting but the query itself).
+// It can be loaded from JSON configuration files (see ParseSearchPresets) or YAML ones (see the presets package).
+type SearchPreset struct {
+	Topic                    SearchQueryTopic         `json:"topic,omitempty" yaml:"topic,omitempty"`
+	SearchDepth              SearchQueryDepth         `json:"search_depth,omitempty" yaml:"search_depth,omitempty"`
+	MaxResults               int                      `json:"max_results,omitempty" yaml:"max_results,omitempty"`
+	TimeRange                SearchQueryTimeRange     `json:"time_range,omitempty" yaml:"time_range,omitempty"`
+	Days                     int                      `json:"days,omitempty" yaml:"days,omitempty"`
+	IncludeAnswer            SearchQueryIncludeAnswer `json:"include_answer,omitempty" yaml:"include_answer,omitempty"`
+	IncludeRawContent        bool                     `json:"include_raw_content,omitempty" yaml:"include_raw_content,omitempty"`
+	IncludeImages            bool                     `json:"include_images,omitempty" yaml:"include_images,omitempty"`
+	IncludeImageDescriptions bool                     `json:"include_image_descriptions,omitempty" yaml:"include_image_descriptions,omitempty"`
+	IncludeDomains           []string                 `json:"include_domains,omitempty" yaml:"include_domains,omitempty"`
+	ExcludeDomains           []string                 `json:"exclude_domains,omitempty" yaml:"exclude_domains,omitempty"`
+}
+
+// Validate returns an error if the settings of the preset are not valid.
+func (sp SearchPreset) Validate() error {
+	return sp.searchQuery("preset").Validate()
+}
+
+// Query returns a builder of a search query using the preset settings.
+func (sp SearchPreset) Query(query string) SearchQueryBuilder {
+	return NewSearchQuery(query).Preset(sp)
+}
+
+func (sp SearchPreset) searchQuery(query string) SearchQuery {
+	return SearchQuery{
+		Query:                    query,
+		Topic:                    sp.Topic,
+		SearchDepth:              sp.SearchDepth,
+		MaxResults:               sp.MaxResults,
+		TimeRange:                sp.TimeRange,
+		Days:                     sp.Days,
+		IncludeAnswer:            sp.IncludeAnswer,
+		IncludeRawContent:        sp.IncludeRawContent,
+		IncludeImages:            sp.IncludeImages,
+		IncludeImageDescriptions: sp.IncludeImageDescriptions,
+		IncludeDomains:           slices.Clone(sp.IncludeDomains),
+		ExcludeDomains:           slices.Clone(sp.ExcludeDomains),
+	}
+}
+
+// SearchPresets holds search presets by name.
+type SearchPresets map[string]SearchPreset
+
+// DefaultSearchPresets are the built in search presets.
+var DefaultSearchPresets = SearchPresets{
+	// Quick factual lookups: few results and a short answer.
+	"fast-lookup": {
+		SearchDepth:   SearchQueryDepthBasic,
+		MaxResults:    3,
+		IncludeAnswer: SearchQueryIncludeAnswerBasic,
+	},
+	// In depth research: many advanced results with their full content and a detailed answer.
+	"deep-research": {
+		SearchDepth:       SearchQueryDepthAdvanced,
+		MaxResults:        SearchMaxPossibleResults,
+		IncludeAnswer:     SearchQueryIncludeAnswerAdvanced,
+		IncludeRawContent: true,
+	},
+	// Latest news of the last days.
+	"news": {
+		Topic:         SearchQueryTopicNews,
+		Days:          3,
+		MaxResults:    10,
+		IncludeAnswer: SearchQueryIncludeAnswerBasic,
+	},
+}
+
+// Query returns a builder of a search query using the preset name.
+func (sp SearchPresets) Query(name, query string) (builder SearchQueryBuilder, err error) {
+	preset, found := sp[name]
+	if !found {
+		return builder, fmt.Errorf("unknown search preset %q", name)
+	}
+	return preset.Query(query), nil
+}
+
+// Validate returns an error if any preset is not valid.
+func (sp SearchPresets) Validate() error {
+	for name, preset := range sp {
+		if err := preset.Validate(); err != nil {
+			return fmt.Errorf("invalid search preset %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// ParseSearchPresets parses and validates search presets by name from JSON, eg:
+//
+//	{"fast-lookup": {"max_results": 3, "include_answer": "basic"}}
+//
+// Unknown settings are rejected. YAML presets can be parsed with the presets package.
+func ParseSearchPresets(data []byte) (presets SearchPresets, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&presets); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal search presets: %w", err)
+	}
+	if err = presets.Validate(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// LoadSearchPresets reads and parses search presets from a JSON file, see ParseSearchPresets.
+func LoadSearchPresets(path string) (presets SearchPresets, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search presets file: %w", err)
+	}
+	return ParseSearchPresets(data)
+}
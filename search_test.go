@@ -0,0 +1,28 @@
+package tavily
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSearchAnswerDeduplicate(t *testing.T) {
+	api := &fakeAPI{handler: func(*http.Request, map[string]any) *http.Response {
+		return jsonResponse(http.StatusOK, `{"query":"q","answer":null,"images":[],"results":[
+			{"title":"1","url":"https://example.com/a","content":"","score":0.9,"raw_content":null},
+			{"title":"2","url":"http://example.com/a/?utm_source=feed","content":"","score":0.8,"raw_content":null},
+			{"title":"3","url":"https://example.com/b","content":"","score":0.7,"raw_content":null}
+		],"response_time":0.1}`)
+	}}
+	answer := testSearch(t, newTestClient(t, api, ClientConfig{}))
+	if len(answer.Results) != 3 {
+		t.Fatalf("expected the client to return the results as sent by the API, got %+v", answer.Results)
+	}
+	if answer = answer.Deduplicate(); len(answer.Results) != 2 || answer.Results[0].Title != "1" || answer.Results[1].Title != "3" {
+		t.Fatalf("unexpected deduplicated results: %+v", answer.Results)
+	}
+	// merging answers
+	answer.Results = append(answer.Results, answer.Results...)
+	if merged := answer.Deduplicate(); len(merged.Results) != 2 {
+		t.Fatalf("expected 2 merged results, got %d", len(merged.Results))
+	}
+}
@@ -2,106 +2,291 @@ package tavily
 
 import (
 	"context"
-	"sync/atomic"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
 )
 
-// session is a sub client that allows to track API usage for a specific session. Instanciate it from the original client.
-type session struct {
-	parent Client
+// SessionConfig allows to customize a session.
+type SessionConfig struct {
+	// Optional name of the session, used to identify it within the sessions tree.
+	Name string
+	// Optional labels of the session (eg "tenant": "X"), allowing to aggregate stats (see StatsByLabel).
+	Labels map[string]string
+	// Optional rate limit of the session, enforced on top of the limits of its parents. Sub sessions share it.
+	RateLimit *RateLimit
+	// Optional share of the parent rate limit allocated to the session, within ]0, 1]. Ignored if RateLimit is set.
+	// The parent rate limit is its own one if set, the one of its closest limited ancestor otherwise or the total
+	// effective rate of the available API keys. It is recomputed before each request, following the changes of the
+	// parent rate (eg a key disabled or throttled by adaptive rate limiting). The shares of the sub sessions of a
+	// session can not sum to more than 1: the share of a session is released when it is closed.
+	RateShare float64
+	// Optional maximum number of in flight requests of the session (including its sub sessions ones).
+	MaxConcurrency int
+	// Optional maximum number of values tracked per tag key by StatsByTag, bounding its memory usage with high cardinality tags
+	// (eg request IDs): the usage of the values beyond it is aggregated under TagOtherValues. Default is DefaultMaxTagValues,
+	// negative is unlimited.
+	MaxTagValues int
+	// Optional store persisting the session stats across restarts. The session must be named, with a name not used by
+	// another open persisted sub session of its parent: its stats are saved under its path within the sessions tree,
+	// restored at creation and checkpointed periodically until the session is closed, which saves a final checkpoint.
+	// Restored stats are added to the parents of the session up to the first persisted one, which already includes them
+	// in its own saved stats if it was persisted as well when they were recorded.
+	StatsStore StatsStore
+	// Optional interval between two checkpoints of the stats into StatsStore. Default is DefaultCheckpointInterval.
+	CheckpointInterval time.Duration
+	// Optional callback receiving the errors of the periodic checkpoints.
+	OnCheckpointError func(error)
+}
+
+// Session is a sub client that allows to track API usage for a specific session. Instanciate it from the original client
+// (NewClientWithConfig returns the root session of a client) or from another session with NewSessionWithConfig.
+// Clients returned by NewClient and NewSession are sessions too and can be type asserted to *Session.
+type Session struct {
+	parent   *Session // nil for the root session
+	root     *mainClient
+	name     string
+	labels   map[string]string
+	children sessionChildren
+	// Controllers
+	limit       *RateLimit
+	share       float64
+	throughput  RateLimiter
+	concurrency semaphore
+	// Stats
 	statsCounter
+	tagged      taggedStats
+	persistence *sessionPersistence
+	// Events
+	subscribers usageSubscribers
+	closed      sync.Once
 }
 
-// Execute a search query using Tavily Search.
-// See https://docs.tavily.com/api-reference/endpoint/search for more information.
-func (s *session) Search(ctx context.Context, query SearchQuery) (answer SearchAnswer, err error) {
-	if answer, err = s.parent.Search(ctx, query); err != nil {
-		return
+func newSession(root *mainClient, parent *Session, conf SessionConfig) (s *Session, err error) {
+	if conf.MaxConcurrency < 0 {
+		return nil, errors.New("max concurrency must be a non-negative integer")
+	}
+	s = &Session{
+		parent:      parent,
+		root:        root,
+		name:        conf.Name,
+		labels:      maps.Clone(conf.Labels),
+		concurrency: newSemaphore(conf.MaxConcurrency),
 	}
-	switch query.SearchDepth {
-	case "", SearchQueryDepthBasic:
-		s.statsCounter.basicSearches.Add(1)
-	case SearchQueryDepthAdvanced:
-		s.statsCounter.advancedSearches.Add(1)
+	if s.tagged.maxValues = conf.MaxTagValues; s.tagged.maxValues == 0 {
+		s.tagged.maxValues = DefaultMaxTagValues
+	}
+	// Rate limiting
+	switch {
+	case conf.RateLimit != nil:
+		limit := *conf.RateLimit
+		if err = limit.validate(); err != nil {
+			return nil, fmt.Errorf("invalid rate limit: %w", err)
+		}
+		s.limit = &limit
+		s.throughput, _ = newRateLimiter(limit, nil, "")
+	case conf.RateShare < 0 || conf.RateShare > 1:
+		return nil, errors.New("rate share must be within ]0, 1]")
+	case conf.RateShare > 0:
+		if parent == nil {
+			return nil, errors.New("a root session can not have a rate share")
+		}
+		if err = parent.children.reserveShare(conf.RateShare); err != nil {
+			return nil, err
+		}
+		s.share = conf.RateShare
+		s.throughput = newShareLimiter(conf.RateShare, parent.reqPerMinute)
+	}
+	// Persistence
+	if conf.StatsStore != nil {
+		if s.persistence, err = s.newPersistence(conf); err != nil {
+			if parent != nil {
+				parent.children.unregister(s)
+			}
+			return nil, fmt.Errorf("invalid persistence configuration: %w", err)
+		}
+	}
+	// Register within the sessions tree
+	if parent != nil {
+		if err = parent.children.register(s); err != nil {
+			parent.children.unregister(s)
+			return nil, err
+		}
+	}
+	// Restore the persisted stats once the name is known to be unique
+	if s.persistence != nil {
+		if err = s.restore(); err != nil {
+			if parent != nil {
+				parent.children.unregister(s)
+			}
+			return nil, fmt.Errorf("failed to restore session stats: %w", err)
+		}
 	}
 	return
 }
 
-// Extract web page content from one or more specified URLs using Tavily Extract.
-// See https://docs.tavily.com/api-reference/endpoint/extract for more infos.
-func (s *session) Extract(ctx context.Context, request ExtractRequest) (answer ExtractAnswer, err error) {
-	if answer, err = s.parent.Extract(ctx, request); err != nil {
-		return
+// reqPerMinute returns the current rate limit of the session: its own one, its share of its parent one or the one of its parent.
+func (s *Session) reqPerMinute() float64 {
+	switch {
+	case s.limit != nil:
+		return float64(s.limit.ReqPerMinute)
+	case s.share > 0:
+		return s.share * s.parent.reqPerMinute()
+	case s.parent != nil:
+		return s.parent.reqPerMinute()
+	default:
+		return s.root.keys.reqPerMinute()
 	}
-	switch request.ExtractDepth {
-	case "", ExtractRequestDepthBasic:
-		s.statsCounter.basicExtracts.Add(int64(len(answer.Results)))
-	case ExtractRequestDepthAdvanced:
-		s.statsCounter.advancedExtracts.Add(int64(len(answer.Results)))
+}
+
+// next returns the client the calls of the session are forwarded to: its parent session or the main client for the root session.
+func (s *Session) next() Client {
+	if s.parent != nil {
+		return s.parent
 	}
-	return
+	return s.root
 }
 
-// Create a child client for a new specific session. This is useful for tracking stats per session.
-func (s *session) NewSession() Client {
-	return &session{
-		parent: s,
+// acquire blocks until the session concurrency limit and rate limit (if any) allow a request.
+// On success, caller must call release once the request is done.
+func (s *Session) acquire(ctx context.Context, c *call) error {
+	if s.root.breaker.isOpen() {
+		return ErrCircuitOpen
+	}
+	waited, err := s.concurrency.acquire(ctx)
+	c.addQueueWait(waited)
+	if err != nil {
+		return fmt.Errorf("failed to wait for session concurrency limiting: %w", err)
+	}
+	if s.throughput != nil {
+		start := time.Now()
+		err = s.throughput.Wait(ctx)
+		c.addRateLimitWait(time.Since(start))
+		if err != nil {
+			s.concurrency.release()
+			return fmt.Errorf("failed to wait for session rate limiting: %w", err)
+		}
 	}
+	return nil
 }
 
-// Stats return the number of searchs (basic and advanced) as well as extract (basic and advanced) performed during this session.
-func (s *session) Stats() Stats {
-	return s.statsCounter.stats()
+func (s *Session) release(c *call) {
+	s.concurrency.release()
+	s.statsCounter.queueWait.Add(c.queueWait.Load())
 }
 
-type statsCounter struct {
-	basicSearches    atomic.Int64
-	advancedSearches atomic.Int64
-	basicExtracts    atomic.Int64
-	advancedExtracts atomic.Int64
+// Execute a search query using Tavily Search.
+// See https://docs.tavily.com/api-reference/endpoint/search for more information.
+func (s *Session) Search(ctx context.Context, query SearchQuery) (answer SearchAnswer, err error) {
+	ctx, c := startCall(ctx, s)
+	var usage Stats
+	defer func() {
+		s.end(c, callResult{
+			operation:    EndpointSearch,
+			depth:        string(query.SearchDepth),
+			usage:        usage,
+			err:          err,
+			searchQuery:  &query,
+			searchAnswer: &answer,
+		})
+	}()
+	if err = s.acquire(ctx, c); err != nil {
+		return
+	}
+	defer s.release(c)
+	if answer, err = s.next().Search(ctx, query); err != nil {
+		return
+	}
+	usage = searchUsage(query)
+	s.statsCounter.add(usage)
+	s.tagged.add(c.tags, usage)
+	return
 }
 
-func (sc *statsCounter) stats() (s Stats) {
-	s.BasicSearches = int(sc.basicSearches.Load())
-	s.AdvancedSearches = int(sc.advancedSearches.Load())
-	s.BasicExtracts = int(sc.basicExtracts.Load())
-	s.AdvancedExtracts = int(sc.advancedExtracts.Load())
+// Extract web page content from one or more specified URLs using Tavily Extract.
+// See https://docs.tavily.com/api-reference/endpoint/extract for more infos.
+func (s *Session) Extract(ctx context.Context, request ExtractRequest) (answer ExtractAnswer, err error) {
+	ctx, c := startCall(ctx, s)
+	var usage Stats
+	defer func() {
+		s.end(c, callResult{
+			operation:      EndpointExtract,
+			depth:          string(request.ExtractDepth),
+			usage:          usage,
+			err:            err,
+			extractRequest: &request,
+			extractAnswer:  &answer,
+		})
+	}()
+	if err = s.acquire(ctx, c); err != nil {
+		return
+	}
+	defer s.release(c)
+	if answer, err = s.next().Extract(ctx, request); err != nil {
+		return
+	}
+	usage = extractUsage(request, answer)
+	s.statsCounter.add(usage)
+	s.tagged.add(c.tags, usage)
 	return
 }
 
-// Stats represents an API usage statistics.
-type Stats struct {
-	BasicSearches    int
-	AdvancedSearches int
-	BasicExtracts    int
-	AdvancedExtracts int
+// Create a child client for a new specific session. This is useful for tracking stats per session.
+func (s *Session) NewSession() Client {
+	child, _ := newSession(s.root, s, SessionConfig{}) // can not fail without config
+	return child
+}
+
+// NewSessionWithConfig creates a sub session with a custom configuration (eg a rate limit).
+func (s *Session) NewSessionWithConfig(conf SessionConfig) (*Session, error) {
+	return newSession(s.root, s, conf)
 }
 
-// BasicSearchesCost will return the API credits cost of the basic searches.
-// See https://docs.tavily.com/guides/api-credits for more infos.
-func (s Stats) BasicSearchesCost() float64 {
-	return float64(s.BasicSearches)
+// Stats return the number of searchs (basic and advanced) as well as extract (basic and advanced) performed during this session.
+func (s *Session) Stats() Stats {
+	return s.statsCounter.stats()
 }
 
-// AdvancedSearchesCost will return the API credits cost of the advanced searches.
-// See https://docs.tavily.com/guides/api-credits for more infos.
-func (s Stats) AdvancedSearchesCost() float64 {
-	return float64(s.AdvancedSearches) * 2
+// ResetStats resets the stats (including the per tag ones) of this session and its sub sessions. Parents stats are not affected.
+func (s *Session) ResetStats() {
+	s.SnapshotAndResetStats()
 }
 
-// BasicExtractsCost will return the API credits cost of the basic extracts.
-// See https://docs.tavily.com/guides/api-credits for more infos.
-func (s Stats) BasicExtractsCost() float64 {
-	return float64(s.BasicExtracts) / 5
+// SnapshotAndResetStats returns the stats of this session and resets them atomically (per counter), allowing periodic reporting.
+// Per tag stats are reset too. The stats of the sub sessions are reset as well, as the stats of a session always include
+// the ones of its sub sessions (see StatsByLabel): snapshot them first if needed. Parents stats are not affected.
+func (s *Session) SnapshotAndResetStats() Stats {
+	for _, child := range s.children.list() {
+		child.ResetStats()
+	}
+	s.tagged.reset()
+	return s.statsCounter.snapshotAndReset()
 }
 
-// AdvancedExtractsCost will return the API credits cost of the advanced extracts.
-// See https://docs.tavily.com/guides/api-credits for more infos.
-func (s Stats) AdvancedExtractsCost() float64 {
-	return (float64(s.AdvancedExtracts) / 5) * 2
+// Keys return the usage statistics of each API key of the client pool.
+func (s *Session) Keys() []KeyStats {
+	return s.root.keys.stats()
 }
 
-// TotalCost will return the total API credits cost of all the searches and extracts.
-// See https://docs.tavily.com/guides/api-credits for more infos.
-func (s Stats) TotalCost() float64 {
-	return s.BasicSearchesCost() + s.AdvancedSearchesCost() + s.BasicExtractsCost() + s.AdvancedExtractsCost()
+// Close closes the sub sessions of the session, removes it from the sessions tree, releases its rate share (if any),
+// stops its background tasks and saves a final checkpoint of its stats if it is persisted (after the ones of its sub sessions).
+// The session can still be used afterwards but its stats will not be persisted anymore.
+func (s *Session) Close() (err error) {
+	s.closed.Do(func() {
+		var errs []error
+		for _, child := range s.children.list() {
+			errs = append(errs, child.Close())
+		}
+		if s.parent != nil {
+			s.parent.children.unregister(s)
+		}
+		if s.persistence != nil {
+			close(s.persistence.stop)
+			errs = append(errs, s.checkpoint(context.Background()))
+		}
+		err = errors.Join(errs...)
+	})
+	return
 }
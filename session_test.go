@@ -0,0 +1,116 @@
+package tavily
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNewClientIsSession(t *testing.T) {
+	client, err := NewClient("tvly-dev-testkey0001", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, ok := client.(*Session)
+	if !ok {
+		t.Fatalf("client is a %T, not a *Session", client)
+	}
+	if _, ok = root.NewSession().(*Session); !ok {
+		t.Fatal("sub session is not a *Session")
+	}
+	if _, err = NewClient("invalid", nil); err == nil {
+		t.Fatal("expected an error for a key with an unknown format")
+	}
+}
+
+func TestSessionStatsIncludeSubSessions(t *testing.T) {
+	root := newTestClient(t, &fakeAPI{}, ClientConfig{})
+	child := root.NewSession()
+	grandChild := child.NewSession()
+	testSearch(t, root)
+	testSearch(t, child)
+	testSearch(t, grandChild)
+	for name, test := range map[string]struct {
+		client   Client
+		expected int
+	}{
+		"root":       {root, 3},
+		"child":      {child, 2},
+		"grandchild": {grandChild, 1},
+	} {
+		if got := test.client.Stats().BasicSearches; got != test.expected {
+			t.Errorf("%s: expected %d searches, got %d", name, test.expected, got)
+		}
+	}
+}
+
+func TestSessionRateShares(t *testing.T) {
+	root := newTestClient(t, &fakeAPI{}, ClientConfig{
+		APIKeys: []APIKey{{Key: "tvly-dev-testkey0001", ReqPerMinute: 1000}},
+	})
+	tenantA, err := root.NewSessionWithConfig(SessionConfig{RateShare: 0.6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tenantA.reqPerMinute(); got != 600 {
+		t.Errorf("expected a rate of 600 req/min, got %g", got)
+	}
+	if _, err = root.NewSessionWithConfig(SessionConfig{RateShare: 0.5}); err == nil {
+		t.Fatal("expected an error when the shares exceed the parent rate")
+	}
+	tenantB, err := root.NewSessionWithConfig(SessionConfig{RateShare: 0.4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// shares of sub sessions apply to the share of their parent
+	nested, err := tenantB.NewSessionWithConfig(SessionConfig{RateShare: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := nested.reqPerMinute(); got != 200 {
+		t.Errorf("expected a rate of 200 req/min, got %g", got)
+	}
+	// closing a session releases its share
+	if err = tenantA.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = root.NewSessionWithConfig(SessionConfig{RateShare: 0.6}); err != nil {
+		t.Fatalf("expected the share of the closed session to be available: %v", err)
+	}
+	if _, err = root.NewSessionWithConfig(SessionConfig{RateShare: 1.5}); err == nil {
+		t.Fatal("expected an error for a share above 1")
+	}
+	if _, err = root.NewSessionWithConfig(SessionConfig{RateShare: -0.1}); err == nil {
+		t.Fatal("expected an error for a negative share")
+	}
+}
+
+func TestSessionRateShareFollowsParent(t *testing.T) {
+	api := &fakeAPI{}
+	root := newTestClient(t, api, ClientConfig{
+		APIKeys: []APIKey{
+			{Key: "tvly-dev-testkey0001", ReqPerMinute: 1000},
+			{Key: "tvly-dev-testkey0002", ReqPerMinute: 1000},
+		},
+	})
+	tenant, err := root.NewSessionWithConfig(SessionConfig{RateShare: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tenant.reqPerMinute(); got != 1000 {
+		t.Fatalf("expected a rate of 1000 req/min, got %g", got)
+	}
+	root.root.keys.keys[1].disable(nil, time.Time{})
+	if got := tenant.reqPerMinute(); got != 500 {
+		t.Fatalf("expected the share to follow the parent rate down to 500 req/min, got %g", got)
+	}
+	limiter := tenant.throughput.(*shareLimiter)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err = limiter.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := limiter.limiter.Limit(); got != reqPerMinuteToLimit(500) {
+		t.Errorf("expected the limiter to be updated to 500 req/min, got %g req/s", got)
+	}
+}
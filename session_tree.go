@@ -0,0 +1,128 @@
+package tavily
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// SessionInfo represents an open session of the sessions tree.
+type SessionInfo struct {
+	Name     string
+	Path     []string // Names of the sessions from the root session to this one (included). Unnamed sessions have an empty name.
+	Labels   map[string]string
+	Stats    Stats
+	Sessions []SessionInfo // Open sub sessions.
+}
+
+// sessionChildren tracks the open sub sessions of a session. A sub session leaves the tree when it is closed.
+type sessionChildren struct {
+	access  sync.Mutex
+	members []*Session
+	shares  float64 // sum of the rate shares of the sub sessions
+}
+
+// register adds child to the sub sessions. Persisted sub sessions must have distinct names as their stats are saved
+// under their path.
+func (sc *sessionChildren) register(child *Session) error {
+	sc.access.Lock()
+	defer sc.access.Unlock()
+	if child.persistence != nil {
+		for _, member := range sc.members {
+			if member.persistence != nil && member.name == child.name {
+				return fmt.Errorf("a persisted sub session named %q is already open", child.name)
+			}
+		}
+	}
+	sc.members = append(sc.members, child)
+	return nil
+}
+
+// unregister removes child from the sub sessions and releases its rate share.
+func (sc *sessionChildren) unregister(child *Session) {
+	sc.access.Lock()
+	defer sc.access.Unlock()
+	sc.members = slices.DeleteFunc(sc.members, func(member *Session) bool { return member == child })
+	sc.shares = max(0, sc.shares-child.share)
+}
+
+// shareEpsilon absorbs the float rounding of the sum of the shares (eg 0.1 + 0.2 + 0.7).
+const shareEpsilon = 1e-9
+
+// reserveShare allocates share of the session rate to a new sub session.
+func (sc *sessionChildren) reserveShare(share float64) error {
+	sc.access.Lock()
+	defer sc.access.Unlock()
+	if sc.shares+share > 1+shareEpsilon {
+		return fmt.Errorf("rate share %g exceeds the %g left by the other sub sessions", share, max(0, 1-sc.shares))
+	}
+	sc.shares += share
+	return nil
+}
+
+// list returns the open sub sessions, in creation order.
+func (sc *sessionChildren) list() []*Session {
+	sc.access.Lock()
+	defer sc.access.Unlock()
+	return slices.Clone(sc.members)
+}
+
+// Name returns the name of the session, empty if unnamed.
+func (s *Session) Name() string {
+	return s.name
+}
+
+// Labels returns a copy of the labels of the session.
+func (s *Session) Labels() map[string]string {
+	return maps.Clone(s.labels)
+}
+
+// path returns the names of the sessions from the root session to this one.
+func (s *Session) path() []string {
+	if s.parent != nil {
+		return append(s.parent.path(), s.name)
+	}
+	return []string{s.name}
+}
+
+// Sessions returns the tree of the open sub sessions of this session. Closed sessions leave the tree (their usage remains
+// included in the stats of their ancestors).
+func (s *Session) Sessions() []SessionInfo {
+	return s.subSessionsInfo(s.path())
+}
+
+func (s *Session) subSessionsInfo(path []string) (infos []SessionInfo) {
+	children := s.children.list()
+	infos = make([]SessionInfo, len(children))
+	for index, child := range children {
+		childPath := append(slices.Clip(path), child.name)
+		infos[index] = SessionInfo{
+			Name:     child.name,
+			Path:     childPath,
+			Labels:   child.Labels(),
+			Stats:    child.Stats(),
+			Sessions: child.subSessionsInfo(childPath),
+		}
+	}
+	return
+}
+
+// StatsByLabel aggregates the stats of this session and its open sub sessions by the values of the label key.
+// As the stats of a session include the ones of its sub sessions (resetting the stats of a session resets its sub
+// sessions ones too), a sub session carrying the same label value as one of its ancestors is not counted twice. Sessions without the label are ignored.
+func (s *Session) StatsByLabel(key string) map[string]Stats {
+	aggregated := make(map[string]Stats)
+	s.aggregateStatsByLabel(key, nil, aggregated)
+	return aggregated
+}
+
+func (s *Session) aggregateStatsByLabel(key string, countedValues []string, aggregated map[string]Stats) {
+	if value, found := s.labels[key]; found && !slices.Contains(countedValues, value) {
+		aggregated[value] = aggregated[value].Add(s.Stats())
+		countedValues = append(slices.Clip(countedValues), value)
+	}
+	for _, child := range s.children.list() {
+		child.aggregateStatsByLabel(key, countedValues, aggregated)
+	}
+}
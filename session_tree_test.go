@@ -0,0 +1,77 @@
+package tavily
+
+import (
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestSessionsTree(t *testing.T) {
+	root := newTestClient(t, &fakeAPI{}, ClientConfig{})
+	tenant, err := root.NewSessionWithConfig(SessionConfig{Name: "tenant-a", Labels: map[string]string{"tenant": "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conversation, err := tenant.NewSessionWithConfig(SessionConfig{Name: "conv-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSearch(t, conversation)
+	sessions := root.Sessions()
+	if len(sessions) != 1 || sessions[0].Name != "tenant-a" || sessions[0].Stats.BasicSearches != 1 {
+		t.Fatalf("unexpected sessions tree: %+v", sessions)
+	}
+	if sub := sessions[0].Sessions; len(sub) != 1 || !slices.Equal(sub[0].Path, []string{"", "tenant-a", "conv-1"}) {
+		t.Fatalf("unexpected sub sessions: %+v", sub)
+	}
+	// sessions stay in the tree until closed, even if not referenced anymore
+	conversation = nil
+	runtime.GC()
+	if sessions = root.Sessions(); len(sessions[0].Sessions) != 1 {
+		t.Fatal("expected the unreferenced session to remain in the tree")
+	}
+	if err = tenant.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if sessions = root.Sessions(); len(sessions) != 0 {
+		t.Fatalf("expected the closed session to leave the tree, got %+v", sessions)
+	}
+	if root.Stats().BasicSearches != 1 {
+		t.Fatal("expected the usage of the closed session to remain in its parent stats")
+	}
+}
+
+func TestStatsByLabel(t *testing.T) {
+	root := newTestClient(t, &fakeAPI{}, ClientConfig{})
+	newLabeled := func(parent *Session, labels map[string]string) *Session {
+		t.Helper()
+		s, err := parent.NewSessionWithConfig(SessionConfig{Labels: labels})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	tenantA := newLabeled(root, map[string]string{"tenant": "a"})
+	tenantASub := newLabeled(tenantA, map[string]string{"tenant": "a", "feature": "chat"})
+	tenantB := newLabeled(root, map[string]string{"tenant": "b"})
+	unlabeled := newLabeled(root, nil)
+	tenantBNested := newLabeled(unlabeled, map[string]string{"tenant": "b"})
+	testSearch(t, tenantA)
+	testSearch(t, tenantASub)
+	testSearch(t, tenantB)
+	testSearch(t, tenantBNested)
+	testSearch(t, unlabeled)
+	byTenant := root.StatsByLabel("tenant")
+	if got := byTenant["a"].BasicSearches; got != 2 {
+		t.Errorf("expected 2 searches for tenant a (sub session not counted twice), got %d", got)
+	}
+	if got := byTenant["b"].BasicSearches; got != 2 {
+		t.Errorf("expected 2 searches for tenant b, got %d", got)
+	}
+	if len(byTenant) != 2 {
+		t.Errorf("unexpected label values: %v", byTenant)
+	}
+	if got := root.StatsByLabel("feature")["chat"].BasicSearches; got != 1 {
+		t.Errorf("expected 1 search for the chat feature, got %d", got)
+	}
+}
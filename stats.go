@@ -0,0 +1,228 @@
+package tavily
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+type statsCounter struct {
+	basicSearches    atomic.Int64
+	advancedSearches atomic.Int64
+	basicExtracts    atomic.Int64
+	advancedExtracts atomic.Int64
+	queueWait        atomic.Int64 // nanoseconds
+}
+
+func (sc *statsCounter) stats() (s Stats) {
+	s.BasicSearches = int(sc.basicSearches.Load())
+	s.AdvancedSearches = int(sc.advancedSearches.Load())
+	s.BasicExtracts = int(sc.basicExtracts.Load())
+	s.AdvancedExtracts = int(sc.advancedExtracts.Load())
+	s.QueueWaitTime = time.Duration(sc.queueWait.Load())
+	return
+}
+
+// add adds s to the counters, eg to restore persisted stats.
+func (sc *statsCounter) add(s Stats) {
+	sc.basicSearches.Add(int64(s.BasicSearches))
+	sc.advancedSearches.Add(int64(s.AdvancedSearches))
+	sc.basicExtracts.Add(int64(s.BasicExtracts))
+	sc.advancedExtracts.Add(int64(s.AdvancedExtracts))
+	sc.queueWait.Add(int64(s.QueueWaitTime))
+}
+
+func (sc *statsCounter) snapshotAndReset() (s Stats) {
+	s.BasicSearches = int(sc.basicSearches.Swap(0))
+	s.AdvancedSearches = int(sc.advancedSearches.Swap(0))
+	s.BasicExtracts = int(sc.basicExtracts.Swap(0))
+	s.AdvancedExtracts = int(sc.advancedExtracts.Swap(0))
+	s.QueueWaitTime = time.Duration(sc.queueWait.Swap(0))
+	return
+}
+
+// searchUsage returns the usage of a successful search.
+func searchUsage(query SearchQuery) (s Stats) {
+	switch query.SearchDepth {
+	case "", SearchQueryDepthBasic:
+		s.BasicSearches = 1
+	case SearchQueryDepthAdvanced:
+		s.AdvancedSearches = 1
+	}
+	return
+}
+
+// extractUsage returns the usage of a successful extract: only the successfully extracted URLs are billed.
+func extractUsage(request ExtractRequest, answer ExtractAnswer) (s Stats) {
+	switch request.ExtractDepth {
+	case "", ExtractRequestDepthBasic:
+		s.BasicExtracts = len(answer.Results)
+	case ExtractRequestDepthAdvanced:
+		s.AdvancedExtracts = len(answer.Results)
+	}
+	return
+}
+
+// Stats represents an API usage statistics.
+type Stats struct {
+	BasicSearches    int
+	AdvancedSearches int
+	BasicExtracts    int
+	AdvancedExtracts int
+	QueueWaitTime    time.Duration // Total time the requests spent waiting for a concurrency slot.
+}
+
+// BasicSearchesCost will return the API credits cost of the basic searches.
+// See https://docs.tavily.com/guides/api-credits for more infos.
+func (s Stats) BasicSearchesCost() float64 {
+	return float64(s.BasicSearches)
+}
+
+// AdvancedSearchesCost will return the API credits cost of the advanced searches.
+// See https://docs.tavily.com/guides/api-credits for more infos.
+func (s Stats) AdvancedSearchesCost() float64 {
+	return float64(s.AdvancedSearches) * 2
+}
+
+// BasicExtractsCost will return the API credits cost of the basic extracts.
+// See https://docs.tavily.com/guides/api-credits for more infos.
+func (s Stats) BasicExtractsCost() float64 {
+	return float64(s.BasicExtracts) / 5
+}
+
+// AdvancedExtractsCost will return the API credits cost of the advanced extracts.
+// See https://docs.tavily.com/guides/api-credits for more infos.
+func (s Stats) AdvancedExtractsCost() float64 {
+	return (float64(s.AdvancedExtracts) / 5) * 2
+}
+
+// TotalCost will return the total API credits cost of all the searches and extracts.
+// See https://docs.tavily.com/guides/api-credits for more infos.
+func (s Stats) TotalCost() float64 {
+	return s.BasicSearchesCost() + s.AdvancedSearchesCost() + s.BasicExtractsCost() + s.AdvancedExtractsCost()
+}
+
+// Add returns the sum of s and other.
+func (s Stats) Add(other Stats) Stats {
+	s.BasicSearches += other.BasicSearches
+	s.AdvancedSearches += other.AdvancedSearches
+	s.BasicExtracts += other.BasicExtracts
+	s.AdvancedExtracts += other.AdvancedExtracts
+	s.QueueWaitTime += other.QueueWaitTime
+	return s
+}
+
+// Sub returns the difference between s and other, eg the usage between two snapshots.
+func (s Stats) Sub(other Stats) Stats {
+	s.BasicSearches -= other.BasicSearches
+	s.AdvancedSearches -= other.AdvancedSearches
+	s.BasicExtracts -= other.BasicExtracts
+	s.AdvancedExtracts -= other.AdvancedExtracts
+	s.QueueWaitTime -= other.QueueWaitTime
+	return s
+}
+
+// statsJSON is the JSON representation of Stats: its fields keep their default keys (the Go field names), the computed costs are added.
+// Durations are in seconds, as the response times of the API answers.
+type statsJSON struct {
+	BasicSearches        int
+	AdvancedSearches     int
+	BasicExtracts        int
+	AdvancedExtracts     int
+	QueueWaitTime        float64
+	BasicSearchesCost    float64
+	AdvancedSearchesCost float64
+	BasicExtractsCost    float64
+	AdvancedExtractsCost float64
+	TotalCost            float64
+}
+
+// statsSnakeCaseJSON is the alternative JSON representation of Stats using the CSV header names, also accepted by UnmarshalJSON.
+type statsSnakeCaseJSON struct {
+	BasicSearches    *int     `json:"basic_searches"`
+	AdvancedSearches *int     `json:"advanced_searches"`
+	BasicExtracts    *int     `json:"basic_extracts"`
+	AdvancedExtracts *int     `json:"advanced_extracts"`
+	QueueWaitTime    *float64 `json:"queue_wait_time"` // seconds
+}
+
+// MarshalJSON includes the computed API credits costs alongside the counters.
+func (s Stats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(statsJSON{
+		BasicSearches:        s.BasicSearches,
+		AdvancedSearches:     s.AdvancedSearches,
+		BasicExtracts:        s.BasicExtracts,
+		AdvancedExtracts:     s.AdvancedExtracts,
+		QueueWaitTime:        s.QueueWaitTime.Seconds(),
+		BasicSearchesCost:    s.BasicSearchesCost(),
+		AdvancedSearchesCost: s.AdvancedSearchesCost(),
+		BasicExtractsCost:    s.BasicExtractsCost(),
+		AdvancedExtractsCost: s.AdvancedExtractsCost(),
+		TotalCost:            s.TotalCost(),
+	})
+}
+
+// UnmarshalJSON restores the counters from their default keys (the Go field names) or their CSV header names.
+// Computed costs are ignored.
+func (s *Stats) UnmarshalJSON(data []byte) (err error) {
+	var tmp statsJSON
+	if err = json.Unmarshal(data, &tmp); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON into tmp struct: %w", err)
+	}
+	s.BasicSearches = tmp.BasicSearches
+	s.AdvancedSearches = tmp.AdvancedSearches
+	s.BasicExtracts = tmp.BasicExtracts
+	s.AdvancedExtracts = tmp.AdvancedExtracts
+	s.QueueWaitTime = time.Duration(tmp.QueueWaitTime * float64(time.Second))
+	var snakeCase statsSnakeCaseJSON
+	if err = json.Unmarshal(data, &snakeCase); err != nil {
+		return fmt.Errorf("failed to unmarshal JSON into snake case tmp struct: %w", err)
+	}
+	for _, field := range []struct {
+		value  *int
+		target *int
+	}{
+		{snakeCase.BasicSearches, &s.BasicSearches},
+		{snakeCase.AdvancedSearches, &s.AdvancedSearches},
+		{snakeCase.BasicExtracts, &s.BasicExtracts},
+		{snakeCase.AdvancedExtracts, &s.AdvancedExtracts},
+	} {
+		if field.value != nil {
+			*field.target = *field.value
+		}
+	}
+	if snakeCase.QueueWaitTime != nil {
+		s.QueueWaitTime = time.Duration(*snakeCase.QueueWaitTime * float64(time.Second))
+	}
+	return
+}
+
+// StatsCSVHeader returns the CSV header matching the records returned by Stats.CSVRecord.
+func StatsCSVHeader() []string {
+	return []string{
+		"basic_searches", "advanced_searches", "basic_extracts", "advanced_extracts", "queue_wait_time",
+		"basic_searches_cost", "advanced_searches_cost", "basic_extracts_cost", "advanced_extracts_cost", "total_cost",
+	}
+}
+
+// CSVRecord returns the stats, including the computed costs, as a CSV record (see StatsCSVHeader) for encoding/csv.
+func (s Stats) CSVRecord() []string {
+	return []string{
+		strconv.Itoa(s.BasicSearches),
+		strconv.Itoa(s.AdvancedSearches),
+		strconv.Itoa(s.BasicExtracts),
+		strconv.Itoa(s.AdvancedExtracts),
+		formatCSVFloat(s.QueueWaitTime.Seconds()),
+		formatCSVFloat(s.BasicSearchesCost()),
+		formatCSVFloat(s.AdvancedSearchesCost()),
+		formatCSVFloat(s.BasicExtractsCost()),
+		formatCSVFloat(s.AdvancedExtractsCost()),
+		formatCSVFloat(s.TotalCost()),
+	}
+}
+
+func formatCSVFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
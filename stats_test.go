@@ -0,0 +1,134 @@
+package tavily
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStatsCosts(t *testing.T) {
+	s := Stats{BasicSearches: 3, AdvancedSearches: 2, BasicExtracts: 10, AdvancedExtracts: 5}
+	for name, test := range map[string]struct {
+		got, expected float64
+	}{
+		"basic searches":    {s.BasicSearchesCost(), 3},
+		"advanced searches": {s.AdvancedSearchesCost(), 4},
+		"basic extracts":    {s.BasicExtractsCost(), 2},
+		"advanced extracts": {s.AdvancedExtractsCost(), 2},
+		"total":             {s.TotalCost(), 11},
+	} {
+		if test.got != test.expected {
+			t.Errorf("%s: expected %g, got %g", name, test.expected, test.got)
+		}
+	}
+}
+
+func TestStatsArithmetic(t *testing.T) {
+	a := Stats{BasicSearches: 3, AdvancedExtracts: 2, QueueWaitTime: time.Second}
+	b := Stats{BasicSearches: 1, AdvancedSearches: 4, QueueWaitTime: time.Millisecond}
+	sum := a.Add(b)
+	if sum != (Stats{BasicSearches: 4, AdvancedSearches: 4, AdvancedExtracts: 2, QueueWaitTime: time.Second + time.Millisecond}) {
+		t.Fatalf("unexpected sum: %+v", sum)
+	}
+	if sum.Sub(b) != a {
+		t.Fatalf("unexpected difference: %+v", sum.Sub(b))
+	}
+}
+
+func TestStatsJSON(t *testing.T) {
+	s := Stats{BasicSearches: 1, AdvancedSearches: 2, BasicExtracts: 5, AdvancedExtracts: 10, QueueWaitTime: 1500 * time.Millisecond}
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys map[string]any
+	if err = json.Unmarshal(data, &keys); err != nil {
+		t.Fatal(err)
+	}
+	// keys of the previous versions (default encoding of the struct) are kept
+	for key, expected := range map[string]float64{
+		"BasicSearches":    1,
+		"AdvancedSearches": 2,
+		"BasicExtracts":    5,
+		"AdvancedExtracts": 10,
+		"QueueWaitTime":    1.5,
+		"TotalCost":        s.TotalCost(),
+	} {
+		if keys[key] != expected {
+			t.Errorf("%s: expected %g, got %v", key, expected, keys[key])
+		}
+	}
+	var decoded Stats
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != s {
+		t.Fatalf("round trip mismatch: %+v", decoded)
+	}
+	for name, test := range map[string]struct {
+		input    string
+		expected Stats
+	}{
+		"legacy": {
+			`{"BasicSearches":1,"AdvancedSearches":2,"BasicExtracts":5,"AdvancedExtracts":10}`,
+			Stats{BasicSearches: 1, AdvancedSearches: 2, BasicExtracts: 5, AdvancedExtracts: 10},
+		},
+		"snake case": {
+			`{"basic_searches":1,"advanced_searches":2,"basic_extracts":5,"advanced_extracts":10,"queue_wait_time":1.5}`,
+			s,
+		},
+	} {
+		decoded = Stats{}
+		if err = json.Unmarshal([]byte(test.input), &decoded); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if decoded != test.expected {
+			t.Errorf("%s: unexpected stats %+v", name, decoded)
+		}
+	}
+}
+
+func TestStatsCSV(t *testing.T) {
+	record := Stats{BasicSearches: 1, BasicExtracts: 1}.CSVRecord()
+	if len(record) != len(StatsCSVHeader()) {
+		t.Fatalf("record has %d columns, header %d", len(record), len(StatsCSVHeader()))
+	}
+	if !slices.Equal(record, []string{"1", "0", "1", "0", "0", "1", "0", "0.2", "0", "1.2"}) {
+		t.Fatalf("unexpected record: %v", record)
+	}
+}
+
+func TestResetStatsCascades(t *testing.T) {
+	root := newTestClient(t, &fakeAPI{}, ClientConfig{})
+	tenant, err := root.NewSessionWithConfig(SessionConfig{Labels: map[string]string{"tenant": "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := tenant.NewSessionWithConfig(SessionConfig{Labels: map[string]string{"tenant": "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testSearch(t, child)
+	testSearch(t, child)
+	// resetting a sub session leaves its parents untouched
+	if snapshot := child.SnapshotAndResetStats(); snapshot.BasicSearches != 2 {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+	if tenant.Stats().BasicSearches != 2 || child.Stats().BasicSearches != 0 {
+		t.Fatal("expected only the sub session stats to be reset")
+	}
+	// resetting a session resets its sub sessions, parents still include children
+	testSearch(t, child)
+	tenant.ResetStats()
+	if tenant.Stats().BasicSearches != 0 || child.Stats().BasicSearches != 0 {
+		t.Fatal("expected the sub session stats to be reset with their parent")
+	}
+	testSearch(t, child)
+	if got := root.StatsByLabel("tenant")["a"].BasicSearches; got != 1 {
+		t.Fatalf("expected 1 search for tenant a after the reset, got %d", got)
+	}
+	if root.Stats().BasicSearches != 4 {
+		t.Fatal("expected the root stats not to be affected")
+	}
+}
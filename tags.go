@@ -0,0 +1,91 @@
+package tavily
+
+import (
+	"context"
+	"maps"
+	"sync"
+)
+
+type tagsCtxKey struct{}
+
+// WithTags returns a copy of ctx carrying cost attribution tags (eg "user_id", "feature", "conversation_id").
+// They are merged with the tags already carried by ctx (new values win). Every session handling a call made with
+// the returned context aggregates its usage per tag (see StatsByTag) and includes the tags in the usage events.
+func WithTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := TagsFromContext(ctx)
+	if merged == nil {
+		merged = make(map[string]string, len(tags))
+	}
+	maps.Copy(merged, tags)
+	return context.WithValue(ctx, tagsCtxKey{}, merged)
+}
+
+// WithTag returns a copy of ctx carrying an additional cost attribution tag. See WithTags.
+func WithTag(ctx context.Context, key, value string) context.Context {
+	return WithTags(ctx, map[string]string{key: value})
+}
+
+// TagsFromContext returns a copy of the cost attribution tags carried by ctx, nil if none.
+func TagsFromContext(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(tagsCtxKey{}).(map[string]string)
+	return maps.Clone(tags)
+}
+
+// DefaultMaxTagValues is the default maximum number of values tracked per tag key by a session (see StatsByTag).
+const DefaultMaxTagValues = 1000
+
+// TagOtherValues is the value under which StatsByTag reports the usage of the values exceeding the tracked values limit.
+const TagOtherValues = "(other)"
+
+// taggedStats aggregates the usage of a session per tag key and value.
+type taggedStats struct {
+	maxValues int // per key, negative for unlimited
+	access    sync.Mutex
+	stats     map[string]map[string]Stats
+}
+
+func (ts *taggedStats) add(tags map[string]string, usage Stats) {
+	if len(tags) == 0 {
+		return
+	}
+	ts.access.Lock()
+	defer ts.access.Unlock()
+	if ts.stats == nil {
+		ts.stats = make(map[string]map[string]Stats, len(tags))
+	}
+	for key, value := range tags {
+		values, found := ts.stats[key]
+		if !found {
+			values = make(map[string]Stats)
+			ts.stats[key] = values
+		}
+		if _, tracked := values[value]; !tracked && ts.maxValues >= 0 && len(values) >= ts.maxValues {
+			// high cardinality tag (eg a request ID): aggregate the new values together
+			value = TagOtherValues
+		}
+		values[value] = values[value].Add(usage)
+	}
+}
+
+func (ts *taggedStats) byTag(key string) map[string]Stats {
+	ts.access.Lock()
+	defer ts.access.Unlock()
+	values := maps.Clone(ts.stats[key])
+	if values == nil {
+		values = make(map[string]Stats)
+	}
+	return values
+}
+
+func (ts *taggedStats) reset() {
+	ts.access.Lock()
+	defer ts.access.Unlock()
+	ts.stats = nil
+}
+
+// StatsByTag returns the usage of this session (including its sub sessions) per value of the cost attribution tag key.
+// Calls made without this tag are not included. Once the maximum number of tracked values of a key is reached
+// (see SessionConfig.MaxTagValues), the usage of the new values is reported under TagOtherValues.
+func (s *Session) StatsByTag(key string) map[string]Stats {
+	return s.tagged.byTag(key)
+}
@@ -0,0 +1,62 @@
+package tavily
+
+import (
+	"context"
+	"strconv"
+	"testing"
+)
+
+func TestStatsByTag(t *testing.T) {
+	root := newTestClient(t, &fakeAPI{}, ClientConfig{})
+	child := root.NewSession().(*Session)
+	ctx := WithTags(context.Background(), map[string]string{"user_id": "42", "feature": "chat"})
+	if _, err := child.Search(WithTag(ctx, "feature", "agent"), SearchQuery{Query: "q"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := child.Search(ctx, SearchQuery{Query: "q"}); err != nil {
+		t.Fatal(err)
+	}
+	testSearch(t, root) // untagged
+	users := root.StatsByTag("user_id")
+	if len(users) != 1 || users["42"].BasicSearches != 2 {
+		t.Errorf("unexpected per user stats: %+v", users)
+	}
+	features := root.StatsByTag("feature")
+	if len(features) != 2 || features["chat"].BasicSearches != 1 || features["agent"].BasicSearches != 1 {
+		t.Errorf("unexpected per feature stats: %+v", features)
+	}
+	if unknown := root.StatsByTag("unknown"); unknown == nil || len(unknown) != 0 {
+		t.Errorf("expected an empty map for an unknown key, got %+v", unknown)
+	}
+	root.ResetStats()
+	if users = child.StatsByTag("user_id"); len(users) != 0 {
+		t.Errorf("expected the sub session tag stats to be reset, got %+v", users)
+	}
+}
+
+func TestStatsByTagCardinalityCap(t *testing.T) {
+	root := newTestClient(t, &fakeAPI{}, ClientConfig{MaxTagValues: 2})
+	unlimited, err := root.NewSessionWithConfig(SessionConfig{MaxTagValues: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 5 {
+		if _, err = unlimited.Search(WithTag(context.Background(), "request_id", strconv.Itoa(i)), SearchQuery{Query: "q"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// already tracked values keep being tracked
+	if _, err = root.Search(WithTag(context.Background(), "request_id", "0"), SearchQuery{Query: "q"}); err != nil {
+		t.Fatal(err)
+	}
+	values := root.StatsByTag("request_id")
+	if len(values) != 3 || values["0"].BasicSearches != 2 || values["1"].BasicSearches != 1 || values[TagOtherValues].BasicSearches != 3 {
+		t.Errorf("unexpected capped stats: %+v", values)
+	}
+	if values = unlimited.StatsByTag("request_id"); len(values) != 5 {
+		t.Errorf("expected all the values to be tracked, got %+v", values)
+	}
+	if session, _ := root.NewSessionWithConfig(SessionConfig{}); session.tagged.maxValues != DefaultMaxTagValues {
+		t.Errorf("unexpected default cap: %d", session.tagged.maxValues)
+	}
+}
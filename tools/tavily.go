@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strconv"
+	"maps"
 	"strings"
 
 	"github.com/hekmon/tavily/v2"
@@ -72,6 +72,18 @@ type OpenAITavilyToolsHelper struct {
 */
 
 func (oaitth OpenAITavilyToolsHelper) GetSearchToolParam() openai.ChatCompletionToolParam {
+	// Reuse the generated schemas of the search query fields the tool parameters are mapped to
+	properties := tavily.SearchQueryJSONSchema()["properties"].(tavily.JSONSchema)
+	category := maps.Clone(properties["topic"].(tavily.JSONSchema))
+	category["description"] = fmt.Sprintf(
+		"The category for the search. Use %q to search for recent news articles. Use the %q parameter to specify the number of days to look back when searching for news articles. The default category is %q, which searches across general topics.",
+		tavily.SearchQueryTopicNews, OpenAISearchToolParamNewsDays, tavily.SearchQueryTopicGeneral,
+	)
+	newsDays := maps.Clone(properties["days"].(tavily.JSONSchema))
+	newsDays["description"] = fmt.Sprintf(
+		"The number of days to look back when searching for news articles. This parameter is only used if the category is set to %q. Default is %d.",
+		string(tavily.SearchQueryTopicNews), defaultSearchNewsDays,
+	)
 	return openai.ChatCompletionToolParam{
 		// Type: constant.Function(""),
 		Function: shared.FunctionDefinitionParam{
@@ -82,25 +94,9 @@ func (oaitth OpenAITavilyToolsHelper) GetSearchToolParam() openai.ChatCompletion
 			Parameters: shared.FunctionParameters{
 				"type": "object",
 				"properties": map[string]any{
-					OpenAISearchToolParamQuery: map[string]any{
-						"type":        "string",
-						"description": "The search query to be performed on the web.",
-					},
-					OpenAISearchToolParamCategory: map[string]any{
-						"type": "string",
-						"description": fmt.Sprintf(
-							"The category for the search. Use %q to search for recent news articles. Use the %q parameter to specify the number of days to look back when searching for news articles. The default category is %q, which searches across general topics.",
-							tavily.SearchQueryTopicNews, OpenAISearchToolParamNewsDays, tavily.SearchQueryTopicGeneral,
-						),
-						"enum": []string{string(tavily.SearchQueryTopicGeneral), string(tavily.SearchQueryTopicNews)},
-					},
-					OpenAISearchToolParamNewsDays: map[string]any{
-						"type": "string",
-						"description": fmt.Sprintf(
-							"The number of days to look back when searching for news articles. This parameter is only used if the category is set to %q. Default is %d.",
-							string(tavily.SearchQueryTopicNews), defaultSearchNewsDays,
-						),
-					},
+					OpenAISearchToolParamQuery:    properties["query"],
+					OpenAISearchToolParamCategory: category,
+					OpenAISearchToolParamNewsDays: newsDays,
 				},
 				"required": []string{OpenAISearchToolParamQuery},
 			},
@@ -108,29 +104,38 @@ func (oaitth OpenAITavilyToolsHelper) GetSearchToolParam() openai.ChatCompletion
 	}
 }
 
+// searchToolParams are the parameters of the search tool, news_days being accepted as a number or a string.
+type searchToolParams struct {
+	Query    string                  `json:"query"`
+	Category tavily.SearchQueryTopic `json:"category"`
+	NewsDays json.Number             `json:"news_days"`
+}
+
 func (oaitth OpenAITavilyToolsHelper) Search(ctx context.Context, toolCallID, params string) (toolResultMsg openai.ChatCompletionToolMessageParam, err error) {
 	// First parse the parameters
-	parsedParams := make(map[string]string, 2)
+	var parsedParams searchToolParams
 	if err = json.Unmarshal([]byte(params), &parsedParams); err != nil {
 		err = fmt.Errorf("failed to parse parameters: %w", err)
 		return
 	}
 	var newsDays int
-	if _, ok := parsedParams[OpenAISearchToolParamCategory]; ok && parsedParams[OpenAISearchToolParamCategory] == string(tavily.SearchQueryTopicNews) {
-		if value, ok := parsedParams[OpenAISearchToolParamNewsDays]; ok {
-			if newsDays, err = strconv.Atoi(value); err != nil {
+	if parsedParams.Category == tavily.SearchQueryTopicNews {
+		if parsedParams.NewsDays != "" {
+			var value int64
+			if value, err = parsedParams.NewsDays.Int64(); err != nil {
 				err = fmt.Errorf("failed to convert news_days parameter to integer: %w", err)
 				return
 			}
+			newsDays = int(value)
 		} else {
 			newsDays = defaultSearchNewsDays
 		}
 	}
 	// Execute the search
 	resp, err := oaitth.client.Search(ctx, tavily.SearchQuery{
-		Query:       parsedParams[OpenAISearchToolParamQuery],
+		Query:       parsedParams.Query,
 		SearchDepth: tavily.SearchQueryDepthAdvanced, // to have a meaningfull content, Advanced is required. Another solution is to query Basic with raw content but this will consome way more tokens.
-		Topic:       tavily.SearchQueryTopic(parsedParams[OpenAISearchToolParamCategory]),
+		Topic:       parsedParams.Category,
 		Days:        newsDays,
 		MaxResults:  oaitth.MaxResults,
 	})
@@ -161,20 +166,21 @@ func (oaitth OpenAITavilyToolsHelper) Search(ctx context.Context, toolCallID, pa
 */
 
 func (oaitth OpenAITavilyToolsHelper) GetExtractToolParam() openai.ChatCompletionToolParam {
+	// Reuse the generated schema of the extract request URLs
+	urls := tavily.ExtractRequestJSONSchema()["properties"].(tavily.JSONSchema)["urls"].(tavily.JSONSchema)
+	url := maps.Clone(urls["items"].(tavily.JSONSchema))
+	url["description"] = "The URL you want to extract content from"
 	return openai.ChatCompletionToolParam{
 		// Type: constant.Function(""),
 		Function: shared.FunctionDefinitionParam{
-			Name: OpenAISearchToolName,
+			Name: OpenAIExtractToolName,
 			Description: param.Opt[string]{
 				Value: "Extract content from a given URL",
 			},
 			Parameters: openai.FunctionParameters{
 				"type": "object",
 				"properties": map[string]any{
-					OpenAIExtractToolParamURL: map[string]any{
-						"type":        "string",
-						"description": "The URL you want to extract content from",
-					},
+					OpenAIExtractToolParamURL: url,
 				},
 				"required": []string{OpenAIExtractToolParamURL},
 			},
@@ -182,16 +188,21 @@ func (oaitth OpenAITavilyToolsHelper) GetExtractToolParam() openai.ChatCompletio
 	}
 }
 
+// extractToolParams are the parameters of the extract tool.
+type extractToolParams struct {
+	URL string `json:"url"`
+}
+
 func (oaitth OpenAITavilyToolsHelper) Extract(ctx context.Context, toolCallID, params string) (toolResultMsg openai.ChatCompletionToolMessageParam, err error) {
 	// First parse the parameters
-	parsedParams := make(map[string]string, 1)
+	var parsedParams extractToolParams
 	if err = json.Unmarshal([]byte(params), &parsedParams); err != nil {
 		err = fmt.Errorf("failed to parse parameters: %w", err)
 		return
 	}
 	// Extract
 	resp, err := oaitth.client.Extract(ctx, tavily.ExtractRequest{
-		URLs:         []string{parsedParams[OpenAIExtractToolParamURL]},
+		URLs:         []string{parsedParams.URL},
 		ExtractDepth: tavily.ExtractRequestDepthAdvanced,
 	})
 	if err != nil {
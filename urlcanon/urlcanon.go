@@ -0,0 +1,235 @@
+// Package urlcanon provides URL canonicalization helpers in order to compare, deduplicate or cache
+// URLs returned by (or sent to) the Tavily API regardless of their cosmetic differences.
+package urlcanon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// Options controls how URLs are canonicalized.
+type Options struct {
+	ForceHTTPS          bool // Rewrite http:// URLs as https:// ones.
+	RemoveTrailingSlash bool // Remove the trailing slash of non root paths.
+	RemoveWWW           bool // Remove the "www." host prefix.
+	KeepFragment        bool // Keep the fragment (removed by default as it is never sent to the server).
+	KeepTrackingParams  bool // Keep the tracking query parameters (see IsTrackingParam).
+}
+
+// Default are the options used by the package level functions.
+var Default = Options{
+	ForceHTTPS:          true,
+	RemoveTrailingSlash: true,
+}
+
+var (
+	// trackingParams are the query parameters known to only be used for tracking purposes.
+	trackingParams = map[string]struct{}{
+		"gclid":   {},
+		"gclsrc":  {},
+		"dclid":   {},
+		"fbclid":  {},
+		"msclkid": {},
+		"yclid":   {},
+		"igshid":  {},
+		"mc_cid":  {},
+		"mc_eid":  {},
+		"_ga":     {},
+		"_gl":     {},
+		"_hsenc":  {},
+		"_hsmi":   {},
+		"mkt_tok": {},
+	}
+	// trackingParamsPrefixes are the query parameters prefixes known to only be used for tracking purposes.
+	trackingParamsPrefixes = []string{"utm_"}
+)
+
+// IsTrackingParam returns true if the query parameter name is a known tracking parameter (utm_*, gclid, fbclid, etc...).
+func IsTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	if _, found := trackingParams[name]; found {
+		return true
+	}
+	for _, prefix := range trackingParamsPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Canonicalize returns the canonical string form of rawURL using the Default options.
+func Canonicalize(rawURL string) (string, error) {
+	return Default.Canonicalize(rawURL)
+}
+
+// Parse parses rawURL and returns its canonical form using the Default options.
+func Parse(rawURL string) (*url.URL, error) {
+	return Default.Parse(rawURL)
+}
+
+// URL returns a canonical copy of u using the Default options. u is not modified.
+func URL(u *url.URL) (*url.URL, error) {
+	return Default.URL(u)
+}
+
+// Equal returns true if both raw URLs share the same canonical form using the Default options.
+// Invalid URLs are compared as is.
+func Equal(a, b string) bool {
+	return Default.Equal(a, b)
+}
+
+// Key returns the canonical form of rawURL suitable as a map key using the Default options.
+// If rawURL can not be canonicalized (it is not a valid absolute URL), it is returned as is.
+func Key(rawURL string) string {
+	return Default.Key(rawURL)
+}
+
+// Canonicalize returns the canonical string form of rawURL.
+func (o Options) Canonicalize(rawURL string) (string, error) {
+	u, err := o.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// Parse parses rawURL and returns its canonical form.
+func (o Options) Parse(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+	return o.URL(u)
+}
+
+// Equal returns true if both raw URLs share the same canonical form.
+// Invalid URLs are compared as is.
+func (o Options) Equal(a, b string) bool {
+	return o.Key(a) == o.Key(b)
+}
+
+// Key returns the canonical form of rawURL suitable as a map key.
+// If rawURL can not be canonicalized (it is not a valid absolute URL), it is returned as is.
+func (o Options) Key(rawURL string) string {
+	canonical, err := o.Canonicalize(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return canonical
+}
+
+// URL returns a canonical copy of u. u is not modified.
+func (o Options) URL(u *url.URL) (*url.URL, error) {
+	if u == nil {
+		return nil, errors.New("nil URL")
+	}
+	if !u.IsAbs() || u.Host == "" {
+		return nil, fmt.Errorf("URL %q is not absolute", u.String())
+	}
+	c := *u
+	// Scheme
+	originalScheme := strings.ToLower(c.Scheme)
+	c.Scheme = originalScheme
+	if o.ForceHTTPS && c.Scheme == "http" {
+		c.Scheme = "https"
+	}
+	// Host
+	host, err := Host(c.Hostname())
+	if err != nil {
+		return nil, err
+	}
+	if o.RemoveWWW {
+		host = strings.TrimPrefix(host, "www.")
+	}
+	// only the default port of the original scheme is removed: http://host:443 is not https://host
+	port := c.Port()
+	if port == defaultPorts[originalScheme] {
+		port = ""
+	}
+	switch {
+	case port != "":
+		c.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		// IPv6
+		c.Host = "[" + host + "]"
+	default:
+		c.Host = host
+	}
+	// Path
+	switch {
+	case c.Path == "":
+		c.Path = "/"
+		c.RawPath = ""
+	case o.RemoveTrailingSlash && len(c.Path) > 1 && strings.HasSuffix(c.Path, "/"):
+		c.Path = strings.TrimRight(c.Path, "/")
+		if c.Path == "" {
+			c.Path = "/"
+		}
+		c.RawPath = strings.TrimRight(c.RawPath, "/")
+	}
+	// Query
+	if c.RawQuery != "" {
+		values, err := url.ParseQuery(c.RawQuery)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse query: %w", err)
+		}
+		if !o.KeepTrackingParams {
+			for name := range values {
+				if IsTrackingParam(name) {
+					delete(values, name)
+				}
+			}
+		}
+		c.RawQuery = values.Encode() // sorted by key
+	}
+	c.ForceQuery = false
+	// Fragment
+	if !o.KeepFragment {
+		c.Fragment = ""
+		c.RawFragment = ""
+	}
+	return &c, nil
+}
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// hostProfile converts internationalized host names like idna.Lookup does, but without enforcing the strict
+// domain names rules (STD3): underscores are common in real world host names (eg "my_service.example.com").
+var hostProfile = idna.New(idna.MapForLookup(), idna.BidiRule(), idna.StrictDomainName(false))
+
+// Host returns the canonical form of a host name: lowercased, without trailing dot and
+// with internationalized domain names converted to their ASCII (punycode) form.
+// IP addresses are returned in their canonical textual form.
+func Host(host string) (string, error) {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	if host == "" {
+		return "", errors.New("empty host")
+	}
+	if ip := net.ParseIP(strings.Trim(host, "[]")); ip != nil {
+		return ip.String(), nil
+	}
+	ascii, err := hostProfile.ToASCII(host)
+	if err != nil {
+		return "", fmt.Errorf("invalid host %q: %w", host, err)
+	}
+	for _, label := range strings.Split(ascii, ".") {
+		if label == "" {
+			return "", fmt.Errorf("invalid host %q: empty label", host)
+		}
+		if index := strings.IndexFunc(label, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+		}); index != -1 {
+			return "", fmt.Errorf("invalid host %q: invalid character %q", host, label[index])
+		}
+	}
+	return ascii, nil
+}
@@ -0,0 +1,136 @@
+package urlcanon
+
+import (
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		options  Options
+		input    string
+		expected string
+	}{
+		{"lowercase scheme and host", Default, "HTTPS://Example.COM/Path", "https://example.com/Path"},
+		{"force https", Default, "http://example.com/a", "https://example.com/a"},
+		{"keep http", Options{}, "http://example.com/a", "http://example.com/a"},
+		{"default https port", Default, "https://example.com:443/a", "https://example.com/a"},
+		{"default http port", Default, "http://example.com:80/a", "https://example.com/a"},
+		{"https port on http", Default, "http://example.com:443/a", "https://example.com:443/a"},
+		{"https port on http kept as http", Options{}, "http://example.com:443/a", "http://example.com:443/a"},
+		{"http port on https", Default, "https://example.com:80/a", "https://example.com:80/a"},
+		{"custom port", Default, "https://example.com:8443/a", "https://example.com:8443/a"},
+		{"empty path", Default, "https://example.com", "https://example.com/"},
+		{"trailing slash", Default, "https://example.com/a/b/", "https://example.com/a/b"},
+		{"root trailing slash", Default, "https://example.com/", "https://example.com/"},
+		{"keep trailing slash", Options{}, "https://example.com/a/", "https://example.com/a/"},
+		{"tracking params", Default, "https://example.com/a?utm_source=x&id=1&UTM_Medium=y&fbclid=z", "https://example.com/a?id=1"},
+		{"keep tracking params", Options{KeepTrackingParams: true}, "https://example.com/a?utm_source=x", "https://example.com/a?utm_source=x"},
+		{"sorted query", Default, "https://example.com/a?b=2&a=1", "https://example.com/a?a=1&b=2"},
+		{"empty query", Default, "https://example.com/a?", "https://example.com/a"},
+		{"fragment", Default, "https://example.com/a#section", "https://example.com/a"},
+		{"keep fragment", Options{KeepFragment: true}, "https://example.com/a#section", "https://example.com/a#section"},
+		{"www", Options{RemoveWWW: true}, "https://www.example.com/", "https://example.com/"},
+		{"trailing dot", Default, "https://example.com./a", "https://example.com/a"},
+		{"idn", Default, "https://Bücher.de/", "https://xn--bcher-kva.de/"},
+		{"punycode", Default, "https://xn--bcher-kva.de/", "https://xn--bcher-kva.de/"},
+		{"underscore", Default, "https://my_service.example.com/", "https://my_service.example.com/"},
+		{"ipv4", Default, "http://127.0.0.1:80/", "https://127.0.0.1/"},
+		{"ipv6", Default, "https://[::1]:443/", "https://[::1]/"},
+		{"ipv6 port", Default, "https://[0:0::1]:8443/", "https://[::1]:8443/"},
+		{"spaces", Default, "  https://example.com/a  ", "https://example.com/a"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.options.Canonicalize(test.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeErrors(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"/relative/path",
+		"example.com/a",
+		"https://",
+		"https://exa mple.com/",
+		"https://-invalid.com/",
+		"https://a..b/",
+		"https://example.com/a?%zz",
+	} {
+		if got, err := Canonicalize(input); err == nil {
+			t.Errorf("%q: expected an error, got %q", input, got)
+		}
+	}
+}
+
+func TestEqualAndKey(t *testing.T) {
+	if !Equal("http://Example.com:80/a/?utm_source=x#top", "https://example.com/a") {
+		t.Error("expected both URLs to be equal")
+	}
+	if Equal("http://example.com:443/a", "https://example.com/a") {
+		t.Error("expected URLs with a non default port to differ")
+	}
+	if got := Key("not a url"); got != "not a url" {
+		t.Errorf("expected an invalid URL to be its own key, got %q", got)
+	}
+}
+
+func TestHost(t *testing.T) {
+	for input, expected := range map[string]string{
+		"Example.COM.":    "example.com",
+		"bücher.de":       "xn--bcher-kva.de",
+		"my_host.example": "my_host.example",
+		"[::1]":           "::1",
+		"192.168.0.1":     "192.168.0.1",
+	} {
+		got, err := Host(input)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", input, err)
+		} else if got != expected {
+			t.Errorf("%q: expected %q, got %q", input, expected, got)
+		}
+	}
+	for _, input := range []string{"", "exa mple.com", "a/b.com", "-bad.com"} {
+		if got, err := Host(input); err == nil {
+			t.Errorf("%q: expected an error, got %q", input, got)
+		}
+	}
+}
+
+func TestIsTrackingParam(t *testing.T) {
+	for name, expected := range map[string]bool{
+		"utm_source":   true,
+		"UTM_CAMPAIGN": true,
+		"gclid":        true,
+		"id":           false,
+		"utm":          false,
+	} {
+		if got := IsTrackingParam(name); got != expected {
+			t.Errorf("%q: expected %v, got %v", name, expected, got)
+		}
+	}
+}
+
+func TestURLDoesNotModifyInput(t *testing.T) {
+	u, err := Parse("https://example.com/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.Fragment = "x"
+	u.Scheme = "HTTP"
+	if _, err = URL(u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Scheme != "HTTP" || u.Fragment != "x" {
+		t.Errorf("input URL has been modified: %s", u)
+	}
+	if _, err = URL(nil); err == nil {
+		t.Error("expected an error for a nil URL")
+	}
+}
@@ -0,0 +1,71 @@
+package tavily
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ValidationError is returned by the requests validation. It lists every invalid field at once, and can be marshaled
+// to JSON (eg to be fed back to an LLM which generated the request).
+type ValidationError struct {
+	Fields []FieldError `json:"invalid_fields"`
+}
+
+func (ve ValidationError) Error() string {
+	fields := make([]string, len(ve.Fields))
+	for index, field := range ve.Fields {
+		fields[index] = field.Error()
+	}
+	return "invalid request: " + strings.Join(fields, "; ")
+}
+
+// Unwrap returns the error of each invalid field.
+func (ve ValidationError) Unwrap() []error {
+	errs := make([]error, len(ve.Fields))
+	for index, field := range ve.Fields {
+		errs[index] = field
+	}
+	return errs
+}
+
+// add records an invalid field.
+func (ve *ValidationError) add(field string, value any, rule string) {
+	ve.Fields = append(ve.Fields, FieldError{
+		Field: field,
+		Value: value,
+		Rule:  rule,
+	})
+}
+
+// err returns the validation error if any field is invalid, nil otherwise.
+func (ve ValidationError) err() error {
+	if len(ve.Fields) == 0 {
+		return nil
+	}
+	return ve
+}
+
+// FieldError represents an invalid field of a request.
+type FieldError struct {
+	Field string `json:"field"` // The JSON name of the field, with the index of the element for lists (eg "urls[2]").
+	Value any    `json:"value"` // The rejected value.
+	Rule  string `json:"rule"`  // The rule the value breaks.
+}
+
+func (fe FieldError) Error() string {
+	value, err := json.Marshal(fe.Value)
+	if err != nil {
+		value = fmt.Appendf(nil, "%v", fe.Value)
+	}
+	return fmt.Sprintf("%s %s (got %s)", fe.Field, fe.Rule, value)
+}
+
+// enumRule returns the rule of an enumerated value.
+func enumRule[T ~string](values ...T) string {
+	quoted := make([]string, len(values))
+	for index, value := range values {
+		quoted[index] = fmt.Sprintf("%q", value)
+	}
+	return "must be one of " + strings.Join(quoted, ", ")
+}
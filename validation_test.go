@@ -0,0 +1,70 @@
+package tavily
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestValidationErrorFields(t *testing.T) {
+	err := SearchQuery{
+		Topic:                    SearchQueryTopicNews,
+		MaxResults:               SearchMaxPossibleResults + 1,
+		TimeRange:                SearchQueryTimeRangeWeek,
+		IncludeAnswer:            "long",
+		IncludeImageDescriptions: true,
+	}.Validate()
+	var verr ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	var fields []string
+	for _, field := range verr.Fields {
+		fields = append(fields, field.Field)
+	}
+	expected := []string{"query", "max_results", "time_range", "include_answer", "include_image_descriptions"}
+	if !slices.Equal(fields, expected) {
+		t.Errorf("expected every invalid field to be reported, got %v", fields)
+	}
+	// each field error is reachable thru the errors package
+	var fieldErr FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "query" {
+		t.Errorf("expected the first field error, got %+v", fieldErr)
+	}
+	if err.Error() != `invalid request: query is required (got ""); max_results must be less than or equal to 20 (got 21); `+
+		`time_range can only be specified when using the "general" topic (got "week"); include_answer must be one of "basic", "advanced" (got "long"); `+
+		`include_image_descriptions can only be true when include_images is true (got true)` {
+		t.Errorf("unexpected message: %s", err)
+	}
+	if (SearchQuery{Query: "q"}).Validate() != nil {
+		t.Error("expected a valid query")
+	}
+}
+
+func TestValidationErrorJSON(t *testing.T) {
+	err := ExtractRequest{URLs: []string{"https://example.com/", "not a url"}, ExtractDepth: "deep"}.Validate()
+	data, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+	expected := `{"invalid_fields":[{"field":"urls[1]","value":"not a url","rule":"must be a valid absolute URL"},` +
+		`{"field":"extract_depth","value":"deep","rule":"must be one of \"basic\", \"advanced\""}]}`
+	if string(data) != expected {
+		t.Errorf("unexpected JSON: %s", data)
+	}
+}
+
+func TestValidationBeforeSending(t *testing.T) {
+	api := &fakeAPI{}
+	root := newTestClient(t, api, ClientConfig{})
+	_, err := root.Extract(context.Background(), ExtractRequest{})
+	var verr ValidationError
+	if !errors.As(err, &verr) || verr.Fields[0].Field != "urls" {
+		t.Errorf("expected a ValidationError, got %v", err)
+	}
+	if len(api.calls()) != 0 {
+		t.Error("expected the invalid request not to be sent")
+	}
+}